@@ -0,0 +1,197 @@
+package gosudachi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+// ConnectionPatternConfig specifies the words on one side of a
+// connection by a raw connection id, a part of speech pattern, a surface
+// or both of the last two. "*" in pos matches anything and the omitted
+// trailing levels are "*".
+type ConnectionPatternConfig struct {
+	Id      *int
+	POS     *[]string
+	Surface *string
+}
+
+// ConnectionPairConfig specifies the connections from the words matching
+// Left to the words matching Right.
+type ConnectionPairConfig struct {
+	Left  *ConnectionPatternConfig
+	Right *ConnectionPatternConfig
+}
+
+// LexiconPlugin is implemented by an EditConnectionCostPlugin that refers
+// to the words. SetLexicon is called before SetUp with the lexicon that
+// contains only the system dictionary, because the user dictionaries are
+// read after the connection costs are edited.
+type LexiconPlugin interface {
+	SetLexicon(lexicon *dictionary.LexiconSet)
+}
+
+// matchPOS reports whether pos matches pattern. "*" in pattern matches
+// anything and the omitted trailing levels of pattern are "*".
+func matchPOS(pattern []string, pos []string) bool {
+	for i, p := range pattern {
+		if p != "*" && p != pos[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkConnectionId checks that id is in the range of the connection
+// matrix of grammar, as the right id of the preceding word if left is
+// true, as the left id of the following word otherwise.
+func checkConnectionId(grammar *dictionary.Grammar, id int, left bool) error {
+	size := grammar.GetRightIdSize()
+	if left {
+		size = grammar.GetLeftIdSize()
+	}
+	if id < 0 || id >= int(size) {
+		return fmt.Errorf("id %d is out of the range of the connection matrix: [0, %d)", id, size)
+	}
+	return nil
+}
+
+// connectionIdResolver resolves connection patterns to the connection
+// ids of the words in the lexicon.
+type connectionIdResolver struct {
+	grammar *dictionary.Grammar
+	lexicon *dictionary.LexiconSet
+	// leftIds and rightIds map a part of speech id to the connection ids
+	// of the words of it. They are built on the first use of a pattern
+	// with pos and without surface.
+	leftIds  map[int16]map[int16]bool
+	rightIds map[int16]map[int16]bool
+}
+
+func newConnectionIdResolver(grammar *dictionary.Grammar, lexicon *dictionary.LexiconSet) *connectionIdResolver {
+	return &connectionIdResolver{
+		grammar: grammar,
+		lexicon: lexicon,
+	}
+}
+
+// scanLexicon builds leftIds and rightIds from the words of the system
+// dictionary, which is the only one in the lexicon given by SetLexicon.
+func (r *connectionIdResolver) scanLexicon() error {
+	posIds, err := r.lexicon.GetPosIds(0)
+	if err != nil {
+		return err
+	}
+	r.leftIds = map[int16]map[int16]bool{}
+	r.rightIds = map[int16]map[int16]bool{}
+	for wordId, posId := range posIds {
+		if r.leftIds[posId] == nil {
+			r.leftIds[posId] = map[int16]bool{}
+			r.rightIds[posId] = map[int16]bool{}
+		}
+		r.leftIds[posId][r.lexicon.GetLeftId(int32(wordId))] = true
+		r.rightIds[posId][r.lexicon.GetRightId(int32(wordId))] = true
+	}
+	return nil
+}
+
+// resolve returns the right ids of the words matching the pattern if
+// left is true, their left ids otherwise. A pattern on the left side of
+// a connection refers to the preceding word, whose right id is used.
+func (r *connectionIdResolver) resolve(pattern *ConnectionPatternConfig, left bool) ([]int16, error) {
+	if pattern == nil {
+		return nil, fmt.Errorf("the pattern is not specified")
+	}
+	if pattern.Id != nil {
+		err := checkConnectionId(r.grammar, *pattern.Id, left)
+		if err != nil {
+			return nil, err
+		}
+		return []int16{int16(*pattern.Id)}, nil
+	}
+	if r.lexicon == nil {
+		return nil, fmt.Errorf("the lexicon is not available")
+	}
+	if pattern.POS == nil && pattern.Surface == nil {
+		return nil, fmt.Errorf("none of id, pos and surface is specified")
+	}
+	if pattern.POS != nil && len(*pattern.POS) > dictionary.PosDepth {
+		return nil, fmt.Errorf("pos is too long")
+	}
+
+	ids := map[int16]bool{}
+	if pattern.Surface != nil {
+		surface := []byte(*pattern.Surface)
+		it := r.lexicon.Lookup(surface, 0)
+		for it.Next() {
+			wordId, end := it.Get()
+			if it.Err() != nil {
+				break
+			}
+			if end != len(surface) {
+				continue
+			}
+			if pattern.POS != nil {
+				posId, err := r.lexicon.GetPosId(wordId)
+				if err != nil {
+					return nil, err
+				}
+				if !matchPOS(*pattern.POS, r.grammar.GetPartOfSpeechString(posId)) {
+					continue
+				}
+			}
+			if left {
+				ids[r.lexicon.GetRightId(wordId)] = true
+			} else {
+				ids[r.lexicon.GetLeftId(wordId)] = true
+			}
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		if r.leftIds == nil {
+			err := r.scanLexicon()
+			if err != nil {
+				return nil, err
+			}
+		}
+		for posId := 0; posId < r.grammar.GetPartOfSpeechSize(); posId++ {
+			if !matchPOS(*pattern.POS, r.grammar.GetPartOfSpeechString(int16(posId))) {
+				continue
+			}
+			m := r.leftIds[int16(posId)]
+			if left {
+				m = r.rightIds[int16(posId)]
+			}
+			for id := range m {
+				ids[id] = true
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no word matches the pattern")
+	}
+
+	ret := make([]int16, 0, len(ids))
+	for id := range ids {
+		ret = append(ret, id)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret, nil
+}
+
+// resolvePair returns the right ids of the left words and the left ids
+// of the right words.
+func (r *connectionIdResolver) resolvePair(pair *ConnectionPairConfig) ([]int16, []int16, error) {
+	leftIds, err := r.resolve(pair.Left, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("left: %s", err)
+	}
+	rightIds, err := r.resolve(pair.Right, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("right: %s", err)
+	}
+	return leftIds, rightIds, nil
+}
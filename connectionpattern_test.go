@@ -0,0 +1,75 @@
+package gosudachi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPOS(t *testing.T) {
+	pos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	for _, tc := range []struct {
+		pattern []string
+		want    bool
+	}{
+		{[]string{"名詞"}, true},
+		{[]string{"*", "普通名詞"}, true},
+		{[]string{"名詞", "*", "一般", "*", "*", "*"}, true},
+		{[]string{}, true},
+		{[]string{"名詞", "固有名詞"}, false},
+		{[]string{"*", "*", "地名"}, false},
+	} {
+		if got := matchPOS(tc.pattern, pos); got != tc.want {
+			t.Errorf("%v: want = %v, got = %v", tc.pattern, tc.want, got)
+		}
+	}
+}
+
+func TestConnectionIdResolver(t *testing.T) {
+	dict := newTestDictionary(t, []PathRewritePlugin{}, []EditConnectionCostPlugin{})
+	defer dict.Close()
+	resolver := newConnectionIdResolver(dict.grammar, dict.lexicon)
+
+	id := 3
+	noun := []string{"名詞"}
+	commonNoun := []string{"名詞", "普通名詞"}
+	godan := []string{"*", "*", "*", "*", "五段-カ行"}
+	tokyo := "東京"
+	miyako := "都"
+	for _, tc := range []struct {
+		pattern *ConnectionPatternConfig
+		want    []int16
+	}{
+		{&ConnectionPatternConfig{Id: &id}, []int16{3}},
+		{&ConnectionPatternConfig{POS: &noun}, []int16{1, 2}},
+		{&ConnectionPatternConfig{POS: &commonNoun}, []int16{2}},
+		{&ConnectionPatternConfig{POS: &godan}, []int16{4}},
+		{&ConnectionPatternConfig{Surface: &tokyo}, []int16{1}},
+		{&ConnectionPatternConfig{Surface: &miyako, POS: &noun}, []int16{2}},
+	} {
+		got, err := resolver.resolve(tc.pattern, true)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", tc.want, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("invalid ids: want = %v, got = %v", tc.want, got)
+		}
+	}
+
+	verb := []string{"動詞"}
+	tooLong := []string{"*", "*", "*", "*", "*", "*", "*"}
+	unknown := "京都"
+	for _, pattern := range []*ConnectionPatternConfig{
+		nil,
+		{},
+		{Surface: &tokyo, POS: &verb},
+		{Surface: &unknown},
+		{POS: &[]string{"形容詞"}},
+		{POS: &tooLong},
+	} {
+		_, err := resolver.resolve(pattern, false)
+		if err == nil {
+			t.Errorf("error is expected for %v", pattern)
+		}
+	}
+}
@@ -78,7 +78,7 @@ func (da *DoubleArray) Open(f *os.File, position int64, totalSize int64) (err er
 	if err != nil {
 		return err
 	}
-	// err = mmap.Madvise(da.buffer, false)
+	// err = mmap.Madvise(da.buffer, mmap.AdviceRandom)
 	// if err != nil {
 	// 	return err
 	// }
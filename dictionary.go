@@ -23,70 +23,125 @@ type JapaneseDictionary struct {
 	oovProviderPlugins []OovProviderPlugin
 	pathRewritePlugins []PathRewritePlugin
 	dictionaries       []*dictionary.BinaryDictionary
+	storageOptions     *dictionary.StorageOptions
 }
 
 func NewJapaneseDictionary(config *BaseConfig, inputTextPlugins []InputTextPlugin, oovProviderPlugins []OovProviderPlugin, pathRewritePlugins []PathRewritePlugin, editConnectionCostPlugins []EditConnectionCostPlugin) (*JapaneseDictionary, error) {
-	if len(oovProviderPlugins) == 0 {
-		return nil, fmt.Errorf("no OOV provider")
+	var failure error
+	d := setUpJapaneseDictionary(config, inputTextPlugins, oovProviderPlugins, pathRewritePlugins, editConnectionCostPlugins, func(where string, i int, err error) bool {
+		failure = err
+		return false
+	})
+	if failure != nil {
+		d.Close()
+		return nil, failure
 	}
+	return d, nil
+}
 
+// setUpJapaneseDictionary reads the dictionaries and sets up the plugins
+// with them. It gives each error to report with the name of the setting
+// of the plugin and its index, or "" and -1 if the error is not of a
+// plugin, and stops if report returns false. The steps needing the
+// system dictionary are skipped if it cannot be read.
+func setUpJapaneseDictionary(config *BaseConfig, inputTextPlugins []InputTextPlugin, oovProviderPlugins []OovProviderPlugin, pathRewritePlugins []PathRewritePlugin, editConnectionCostPlugins []EditConnectionCostPlugin, report func(where string, i int, err error) bool) *JapaneseDictionary {
 	d := &JapaneseDictionary{
 		inputTextPlugins:   inputTextPlugins,
 		oovProviderPlugins: oovProviderPlugins,
 		pathRewritePlugins: pathRewritePlugins,
+		storageOptions:     config.storageOptions(),
+	}
+
+	if len(oovProviderPlugins) == 0 {
+		if !report("", -1, fmt.Errorf("no OOV provider")) {
+			return d
+		}
 	}
 
 	err := d.ReadSystemDictionary(config.SystemDict, config.Utf16String)
 	if err != nil {
-		return nil, fmt.Errorf("fail to read a system dictionary: %s", err)
+		if !report("", -1, fmt.Errorf("fail to read a system dictionary: %s", err)) {
+			return d
+		}
 	}
+	hasGrammar := err == nil
+	// the costs of the words of the user dictionaries are calculated by
+	// tokenizing them, which needs the plugins set up
+	canTokenize := hasGrammar && len(oovProviderPlugins) > 0
 
-	for _, plugin := range editConnectionCostPlugins {
-		err := plugin.SetUp(d.grammar)
-		if err != nil {
-			return nil, err
+	if hasGrammar {
+		for i, plugin := range editConnectionCostPlugins {
+			if lp, ok := plugin.(LexiconPlugin); ok {
+				lp.SetLexicon(d.lexicon)
+			}
+			err := plugin.SetUp(d.grammar)
+			if err == nil {
+				err = plugin.Edit(d.grammar)
+			}
+			if err != nil && !report("editConnectionCostPlugin", i, err) {
+				return d
+			}
 		}
-		err = plugin.Edit(d.grammar)
+
+		err = d.ReadCharacterDefinition(config.CharacterDefinitionFile)
 		if err != nil {
-			return nil, err
+			canTokenize = false
+			if !report("", -1, fmt.Errorf("fail to read a character defition file: %s", err)) {
+				return d
+			}
 		}
 	}
 
-	err = d.ReadCharacterDefinition(config.CharacterDefinitionFile)
-	if err != nil {
-		return nil, fmt.Errorf("fail to read a character defition file: %s", err)
-	}
-
-	for _, plugin := range inputTextPlugins {
+	for i, plugin := range inputTextPlugins {
 		err := plugin.SetUp()
 		if err != nil {
-			return nil, err
+			canTokenize = false
+			if !report("inputTextPlugin", i, err) {
+				return d
+			}
 		}
 	}
-	for _, plugin := range oovProviderPlugins {
+	if !hasGrammar {
+		return d
+	}
+	for i, plugin := range oovProviderPlugins {
 		err := plugin.SetUp(d.grammar)
 		if err != nil {
-			return nil, err
+			canTokenize = false
+			if !report("oovProviderPlugin", i, err) {
+				return d
+			}
 		}
 	}
-	for _, plugin := range pathRewritePlugins {
+	for i, plugin := range pathRewritePlugins {
 		err := plugin.SetUp(d.grammar)
-		if err != nil {
-			return nil, err
+		if err != nil && !report("pathRewritePlugin", i, err) {
+			return d
 		}
 	}
 
 	for _, ud := range config.UserDict {
-		err := d.ReadUserDictionary(ud, config.Utf16String)
+		var err error
+		if canTokenize {
+			err = d.ReadUserDictionary(ud, config.Utf16String)
+		} else {
+			var dict *dictionary.BinaryDictionary
+			dict, err = dictionary.ReadUserDictionaryWithStorage(ud, config.Utf16String, d.storageOptions)
+			if err == nil {
+				dict.Close()
+			}
+		}
 		if err != nil {
-			return nil, fmt.Errorf("fail to read a user dictionary: %s", err)
+			if !report("", -1, fmt.Errorf("fail to read a user dictionary: %s", err)) {
+				return d
+			}
 		}
 	}
-	return d, nil
+	return d
 }
 
 func (d *JapaneseDictionary) ReadSystemDictionary(filename string, utf16string bool) error {
-	dict, err := dictionary.ReadSystemDictionary(filename, utf16string)
+	dict, err := dictionary.ReadSystemDictionaryWithStorage(filename, utf16string, d.storageOptions)
 	if err != nil {
 		return err
 	}
@@ -102,7 +157,7 @@ func (d *JapaneseDictionary) ReadUserDictionary(filename string, utf16string boo
 		return fmt.Errorf("too many dictionaries")
 	}
 
-	dict, err := dictionary.ReadUserDictionary(filename, utf16string)
+	dict, err := dictionary.ReadUserDictionaryWithStorage(filename, utf16string, d.storageOptions)
 	if err != nil {
 		return err
 	}
@@ -188,3 +243,46 @@ func (d *JapaneseDictionary) GetPartOfSpeechSize() int {
 func (d *JapaneseDictionary) GetPartOfSpeechString(posId int16) []string {
 	return d.grammar.GetPartOfSpeechString(posId)
 }
+
+// DictionaryWord is a word found by JapaneseDictionary.Lookup.
+type DictionaryWord struct {
+	WordId       int32
+	DictionaryId int
+	LeftId       int16
+	RightId      int16
+	Cost         int16
+	WordInfo     *dictionary.WordInfo
+}
+
+// Lookup returns the words whose headword is text. The text is looked up
+// as it is, without the rewriting by the InputTextPlugins.
+func (d *JapaneseDictionary) Lookup(text string) ([]*DictionaryWord, error) {
+	b := []byte(text)
+	ret := []*DictionaryWord{}
+	it := d.lexicon.Lookup(b, 0)
+	for it.Next() {
+		wordId, end := it.Get()
+		if it.Err() != nil {
+			break
+		}
+		if end != len(b) {
+			continue
+		}
+		wi, err := d.lexicon.GetWordInfo(wordId)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &DictionaryWord{
+			WordId:       wordId,
+			DictionaryId: d.lexicon.GetDictionaryId(wordId),
+			LeftId:       d.lexicon.GetLeftId(wordId),
+			RightId:      d.lexicon.GetRightId(wordId),
+			Cost:         d.lexicon.GetCost(wordId),
+			WordInfo:     wi,
+		})
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -2,40 +2,47 @@ package dictionary
 
 import (
 	"fmt"
-	"github.com/msnoigrs/gosudachi/internal/mmap"
-	"os"
 )
 
 type BinaryDictionary struct {
-	fd      *os.File
-	fmap    []byte
+	storage Storage
 	Header  *DictionaryHeader
 	Grammar *Grammar
 	Lexicon *DoubleArrayLexicon
 }
 
 func NewBinaryDictionary(filename string, utf16string bool) (*BinaryDictionary, error) {
-	fd, err := os.OpenFile(filename, os.O_RDONLY, 0644)
-	if err != nil {
-		return nil, err
-	}
+	return NewBinaryDictionaryWithStorage(filename, utf16string, nil)
+}
 
-	finfo, err := fd.Stat()
+// NewBinaryDictionaryWithStorage is the same as NewBinaryDictionary except
+// that the file is loaded as options tell. options may be nil.
+func NewBinaryDictionaryWithStorage(filename string, utf16string bool, options *StorageOptions) (*BinaryDictionary, error) {
+	storage, err := OpenStorage(filename, utf16string, options)
 	if err != nil {
-		_ = fd.Close()
 		return nil, err
 	}
-	fmap, err := mmap.Mmap(fd, false, 0, finfo.Size())
-	if err != nil {
-		_ = fd.Close()
-		return nil, err
+	fmap := storage.Bytes()
+	if len(fmap) < HeaderStorageSize {
+		_ = storage.Close()
+		return nil, fmt.Errorf("invalid header: %s", filename)
 	}
 
 	offset := 0
 	header := ParseDictionaryHeader(fmap, offset)
 	if header == nil {
+		_ = storage.Close()
 		return nil, fmt.Errorf("invalid header: %s", filename)
 	}
+	if r := storage.ReaderAt(); r != nil {
+		err = header.ReadMetadataAt(r, storage.Size())
+	} else {
+		err = header.ReadMetadata(fmap)
+	}
+	if err != nil {
+		_ = storage.Close()
+		return nil, fmt.Errorf("%s: %s", err, filename)
+	}
 
 	offset += HeaderStorageSize
 	var grammar *Grammar
@@ -43,16 +50,22 @@ func NewBinaryDictionary(filename string, utf16string bool) (*BinaryDictionary,
 		grammar = NewGrammar(fmap, offset, utf16string)
 		offset += grammar.StorageSize
 	} else if header.Version != UserDictVersion {
-		_ = mmap.Munmap(fmap)
-		_ = fd.Close()
+		_ = storage.Close()
 		return nil, fmt.Errorf("invalid dictionary: %s", filename)
 	}
 
 	lexicon := NewDoubleArrayLexicon(fmap, offset, utf16string)
+	if r := storage.ReaderAt(); r != nil {
+		end, err := metadataOffset(r, storage.Size())
+		if err != nil {
+			_ = storage.Close()
+			return nil, fmt.Errorf("%s: %s", err, filename)
+		}
+		lexicon.wordInfos.setReader(r, end)
+	}
 
 	return &BinaryDictionary{
-		fd,
-		fmap,
+		storage,
 		header,
 		grammar,
 		lexicon,
@@ -60,7 +73,11 @@ func NewBinaryDictionary(filename string, utf16string bool) (*BinaryDictionary,
 }
 
 func ReadSystemDictionary(filename string, utf16string bool) (*BinaryDictionary, error) {
-	dict, err := NewBinaryDictionary(filename, utf16string)
+	return ReadSystemDictionaryWithStorage(filename, utf16string, nil)
+}
+
+func ReadSystemDictionaryWithStorage(filename string, utf16string bool, options *StorageOptions) (*BinaryDictionary, error) {
+	dict, err := NewBinaryDictionaryWithStorage(filename, utf16string, options)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +89,11 @@ func ReadSystemDictionary(filename string, utf16string bool) (*BinaryDictionary,
 }
 
 func ReadUserDictionary(filename string, utf16string bool) (*BinaryDictionary, error) {
-	dict, err := NewBinaryDictionary(filename, utf16string)
+	return ReadUserDictionaryWithStorage(filename, utf16string, nil)
+}
+
+func ReadUserDictionaryWithStorage(filename string, utf16string bool, options *StorageOptions) (*BinaryDictionary, error) {
+	dict, err := NewBinaryDictionaryWithStorage(filename, utf16string, options)
 	if err != nil {
 		return nil, err
 	}
@@ -84,9 +105,5 @@ func ReadUserDictionary(filename string, utf16string bool) (*BinaryDictionary, e
 }
 
 func (bd *BinaryDictionary) Close() error {
-	err := mmap.Munmap(bd.fmap)
-	if err != nil {
-		return err
-	}
-	return bd.fd.Close()
+	return bd.storage.Close()
 }
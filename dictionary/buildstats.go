@@ -0,0 +1,150 @@
+package dictionary
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// BuildPhase is a phase of building a dictionary.
+type BuildPhase int
+
+const (
+	PhaseParse BuildPhase = iota
+	PhasePosTable
+	PhaseMatrix
+	PhaseTrie
+	PhaseWordIdTable
+	PhaseWordParameters
+	PhaseWordInfo
+	PhaseWordInfoOffsets
+	numBuildPhases
+)
+
+var buildPhaseNames = [numBuildPhases]string{
+	"parse",
+	"POS table",
+	"connection matrix",
+	"trie",
+	"word-ID table",
+	"word parameters",
+	"wordInfos",
+	"wordInfo offsets",
+}
+
+func (phase BuildPhase) String() string {
+	if phase < 0 || phase >= numBuildPhases {
+		return fmt.Sprintf("BuildPhase(%d)", int(phase))
+	}
+	return buildPhaseNames[phase]
+}
+
+// BuildProgressFunc is called while DictionaryBuilder is working.
+// A phase is reported with state 0 when it begins and with state == max
+// when it ends. max is -1 if the amount of work is not known in advance,
+// as is the case for PhaseParse.
+type BuildProgressFunc func(phase BuildPhase, state int, max int)
+
+// BuildStats holds statistics collected by DictionaryBuilder.
+type BuildStats struct {
+	Entries      int
+	EntriesByPos map[int16]int      // by part of speech id
+	Pos          map[int16][]string // the parts of speech of EntriesByPos
+	TrieSize     int                // number of double-array units
+	Sizes        [numBuildPhases]int64
+	Elapsed      [numBuildPhases]time.Duration
+}
+
+func newBuildStats() *BuildStats {
+	return &BuildStats{
+		EntriesByPos: map[int16]int{},
+		Pos:          map[int16][]string{},
+	}
+}
+
+// TotalSize returns the number of bytes written except the header.
+func (stats *BuildStats) TotalSize() int64 {
+	var total int64
+	for _, size := range stats.Sizes {
+		total += size
+	}
+	return total
+}
+
+// TotalElapsed returns the time spent on all the phases.
+func (stats *BuildStats) TotalElapsed() time.Duration {
+	var total time.Duration
+	for _, elapsed := range stats.Elapsed {
+		total += elapsed
+	}
+	return total
+}
+
+// NewBuildProgressPrinter returns a BuildProgressFunc which writes the
+// progress of each phase to output. Section sizes are taken from stats.
+func NewBuildProgressPrinter(output io.Writer, stats *BuildStats) BuildProgressFunc {
+	p := message.NewPrinter(language.English)
+	return func(phase BuildPhase, state int, max int) {
+		switch {
+		case max < 0:
+			fmt.Fprint(output, ".")
+		case state == 0:
+			if phase == PhaseTrie {
+				fmt.Fprint(output, "building the trie")
+			} else {
+				fmt.Fprintf(output, "writing the %s...", phase)
+			}
+		case state >= max:
+			p.Fprintf(output, " %d bytes\n", stats.Sizes[phase])
+		case state%((max/10)+1) == 0:
+			fmt.Fprint(output, ".")
+		}
+	}
+}
+
+// PrintBuildStats writes a report of stats to output.
+// Entry counts by part of speech are included if verbose is true.
+func PrintBuildStats(stats *BuildStats, verbose bool, output io.Writer) {
+	p := message.NewPrinter(language.English)
+
+	p.Fprintf(output, "entries: %d\n", stats.Entries)
+	p.Fprintf(output, "parts of speech: %d\n", len(stats.EntriesByPos))
+	p.Fprintf(output, "trie size: %d units\n", stats.TrieSize)
+	fmt.Fprintln(output, "sections:")
+	for phase := PhasePosTable; phase < numBuildPhases; phase++ {
+		p.Fprintf(output, "  %-18s %14d bytes\n", phase.String()+":", stats.Sizes[phase])
+	}
+	p.Fprintf(output, "  %-18s %14d bytes\n", "total:", stats.TotalSize())
+	fmt.Fprintln(output, "elapsed time:")
+	for phase := PhaseParse; phase < numBuildPhases; phase++ {
+		fmt.Fprintf(output, "  %-18s %14s\n", phase.String()+":", stats.Elapsed[phase].Round(time.Millisecond))
+	}
+	fmt.Fprintf(output, "  %-18s %14s\n", "total:", stats.TotalElapsed().Round(time.Millisecond))
+
+	if !verbose {
+		return
+	}
+	type posCount struct {
+		pos   string
+		count int
+	}
+	poss := make([]posCount, 0, len(stats.EntriesByPos))
+	for posId, count := range stats.EntriesByPos {
+		poss = append(poss, posCount{strings.Join(stats.Pos[posId], ","), count})
+	}
+	sort.Slice(poss, func(i, j int) bool {
+		if poss[i].count != poss[j].count {
+			return poss[i].count > poss[j].count
+		}
+		return poss[i].pos < poss[j].pos
+	})
+	fmt.Fprintln(output, "entries by part of speech:")
+	for _, pc := range poss {
+		p.Fprintf(output, "  %10d %s\n", pc.count, pc.pos)
+	}
+}
@@ -0,0 +1,112 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+func TestBuildStats(t *testing.T) {
+	lexicon := `東京,0,0,2816,東京,名詞,固有名詞,地名,一般,*,*,トウキョウ,東京,*,A,*,*,*
+都,0,0,2914,都,名詞,普通名詞,一般,*,*,*,ト,都,*,A,*,*,*
+京都,0,0,2914,京都,名詞,固有名詞,地名,一般,*,*,キョウト,京都,*,A,*,*,*
+行く,0,0,5105,行く,動詞,非自立可能,*,*,五段-カ行,終止形-一般,イク,行く,*,A,*,*,*
+`
+	f, err := ioutil.TempFile("", "buildstats")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	hb, err := NewDictionaryHeader(SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var progress bytes.Buffer
+	dicbuilder := NewDictionaryBuilder(int64(len(hb)), nil, false)
+	stats := dicbuilder.Stats()
+	dicbuilder.SetProgressFunc(NewBuildProgressPrinter(&progress, stats))
+	store := NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(lexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader("1 1\n0 0 0\n"), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if stats.Entries != 4 {
+		t.Errorf("entries: want = 4, got = %d", stats.Entries)
+	}
+	wants := map[string]int{
+		"名詞,固有名詞,地名,一般,*,*":         2,
+		"名詞,普通名詞,一般,*,*,*":          1,
+		"動詞,非自立可能,*,*,五段-カ行,終止形-一般": 1,
+	}
+	if len(stats.EntriesByPos) != len(wants) {
+		t.Errorf("parts of speech: want = %d, got = %d", len(wants), len(stats.EntriesByPos))
+	}
+	for pos, want := range wants {
+		posId := store.GetPosId(strings.Split(pos, ",")...)
+		if got := stats.EntriesByPos[posId]; got != want {
+			t.Errorf("%s: want = %d, got = %d", pos, want, got)
+		}
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := fi.Size() - int64(len(hb)); stats.TotalSize() != want {
+		t.Errorf("total size: want = %d, got = %d", want, stats.TotalSize())
+	}
+
+	// a line for each phase, with the dots between the label and the size
+	p := message.NewPrinter(language.English)
+	lines := strings.Split(progress.String(), "\n")
+	if len(lines) != int(numBuildPhases-PhasePosTable)+1 {
+		t.Fatalf("invalid progress: %q", progress.String())
+	}
+	for phase := PhasePosTable; phase < numBuildPhases; phase++ {
+		line := lines[phase-PhasePosTable]
+		label := fmt.Sprintf("writing the %s...", phase)
+		if phase == PhaseTrie {
+			label = "building the trie"
+		}
+		size := p.Sprintf(" %d bytes", stats.Sizes[phase])
+		if !strings.HasPrefix(line, label) || !strings.HasSuffix(line, size) || strings.Trim(line[len(label):len(line)-len(size)], ".") != "" {
+			t.Errorf("%s: want = %s...%s, got = %s", phase, label, size, line)
+		}
+	}
+
+	var output bytes.Buffer
+	PrintBuildStats(stats, true, &output)
+	for _, want := range []string{
+		"entries: 4\n",
+		"parts of speech: 3\n",
+		"entries by part of speech:\n" +
+			"           2 名詞,固有名詞,地名,一般,*,*\n" +
+			"           1 動詞,非自立可能,*,*,五段-カ行,終止形-一般\n" +
+			"           1 名詞,普通名詞,一般,*,*,*\n",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("%q is not found in %q", want, output.String())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package dictionary
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/msnoigrs/gosudachi/dartsclone"
@@ -97,6 +98,8 @@ type wordInfoList struct {
 	offset          int
 	wordSize        int32
 	bufferToStringF bufferToStringFunc
+	reader          io.ReaderAt // reads wordInfos not in bytebuffer
+	end             int64       // the end of the last wordInfo in reader
 }
 
 func newWordInfoList(bytebuffer []byte, offset int, wordSize int32, bufferToStringF bufferToStringFunc) *wordInfoList {
@@ -108,28 +111,66 @@ func newWordInfoList(bytebuffer []byte, offset int, wordSize int32, bufferToStri
 	}
 }
 
-func (l *wordInfoList) getWordInfo(wordId int32) *WordInfo {
+func (l *wordInfoList) setReader(reader io.ReaderAt, end int64) {
+	l.reader = reader
+	l.end = end
+}
+
+// readWordInfo reads the bytes of a wordInfo, which end at the beginning
+// of the next one.
+func (l *wordInfoList) readWordInfo(wordId int32, index int) ([]byte, error) {
+	end := l.end
+	if wordId+1 < l.wordSize {
+		next := int64(l.wordIdToOffset(wordId + 1))
+		if next > int64(index) {
+			end = next
+		}
+	}
+	if end <= int64(index) {
+		return nil, fmt.Errorf("wordInfo %d is out of the lexicon", wordId)
+	}
+	buf := make([]byte, end-int64(index))
+	_, err := l.reader.ReadAt(buf, int64(index))
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("fail to read wordInfo %d: %s", wordId, err)
+	}
+	return buf, nil
+}
+
+func (l *wordInfoList) getWordInfo(wordId int32) (*WordInfo, error) {
+	bytebuffer := l.bytebuffer
 	index := l.wordIdToOffset(wordId)
+	if l.reader != nil {
+		var err error
+		bytebuffer, err = l.readWordInfo(wordId, index)
+		if err != nil {
+			return nil, err
+		}
+		index = 0
+	}
 
-	index, surface := l.bufferToStringF(l.bytebuffer, index)
-	index, headwordLength := bufferToStringLength(l.bytebuffer, index)
-	index, posId := bufferToInt16(l.bytebuffer, index)
-	index, normalizedForm := l.bufferToStringF(l.bytebuffer, index)
+	index, surface := l.bufferToStringF(bytebuffer, index)
+	index, headwordLength := bufferToStringLength(bytebuffer, index)
+	index, posId := bufferToInt16(bytebuffer, index)
+	index, normalizedForm := l.bufferToStringF(bytebuffer, index)
 	if normalizedForm == "" {
 		normalizedForm = surface
 	}
-	index, dictionaryFormWordId := bufferToInt32(l.bytebuffer, index)
-	index, readingForm := l.bufferToStringF(l.bytebuffer, index)
+	index, dictionaryFormWordId := bufferToInt32(bytebuffer, index)
+	index, readingForm := l.bufferToStringF(bytebuffer, index)
 	if readingForm == "" {
 		readingForm = surface
 	}
-	index, aUnitSplit := bufferToInt32Array(l.bytebuffer, index)
-	index, bUnitSplit := bufferToInt32Array(l.bytebuffer, index)
-	index, wordStructure := bufferToInt32Array(l.bytebuffer, index)
+	index, aUnitSplit := bufferToInt32Array(bytebuffer, index)
+	index, bUnitSplit := bufferToInt32Array(bytebuffer, index)
+	index, wordStructure := bufferToInt32Array(bytebuffer, index)
 
 	dictionaryForm := surface
 	if dictionaryFormWordId >= 0 && dictionaryFormWordId != wordId {
-		wi := l.getWordInfo(dictionaryFormWordId)
+		wi, err := l.getWordInfo(dictionaryFormWordId)
+		if err != nil {
+			return nil, err
+		}
 		dictionaryForm = wi.Surface
 	}
 
@@ -144,7 +185,80 @@ func (l *wordInfoList) getWordInfo(wordId int32) *WordInfo {
 		AUnitSplit:           aUnitSplit,
 		BUnitSplit:           bUnitSplit,
 		WordStructure:        wordStructure,
+	}, nil
+}
+
+// getPosId reads the part of speech of a wordInfo without decoding the
+// others.
+func (l *wordInfoList) getPosId(wordId int32) (int16, error) {
+	bytebuffer := l.bytebuffer
+	index := l.wordIdToOffset(wordId)
+	if l.reader != nil {
+		var err error
+		bytebuffer, err = l.readWordInfo(wordId, index)
+		if err != nil {
+			return 0, err
+		}
+		index = 0
 	}
+	return l.bufferToPosId(bytebuffer, index), nil
+}
+
+func (l *wordInfoList) bufferToPosId(bytebuffer []byte, index int) int16 {
+	index, _ = l.bufferToStringF(bytebuffer, index)
+	index, _ = bufferToStringLength(bytebuffer, index)
+	_, posId := bufferToInt16(bytebuffer, index)
+	return posId
+}
+
+// posIdChunkSize is the size of the wordInfos read at once by getPosIds.
+const posIdChunkSize = 1 << 20
+
+// getPosIds returns the parts of speech of all the words. The wordInfos
+// in reader are read in chunks instead of one by one.
+func (l *wordInfoList) getPosIds() ([]int16, error) {
+	ret := make([]int16, l.wordSize)
+	if l.reader == nil {
+		for wordId := range ret {
+			ret[wordId] = l.bufferToPosId(l.bytebuffer, l.wordIdToOffset(int32(wordId)))
+		}
+		return ret, nil
+	}
+
+	var (
+		chunk      []byte
+		chunkBegin int64
+	)
+	for wordId := int32(0); wordId < l.wordSize; wordId++ {
+		begin := int64(l.wordIdToOffset(wordId))
+		end := l.end
+		if wordId+1 < l.wordSize {
+			next := int64(l.wordIdToOffset(wordId + 1))
+			if next > begin {
+				end = next
+			}
+		}
+		if end <= begin {
+			return nil, fmt.Errorf("wordInfo %d is out of the lexicon", wordId)
+		}
+		if begin < chunkBegin || end > chunkBegin+int64(len(chunk)) {
+			chunkEnd := begin + posIdChunkSize
+			if chunkEnd < end {
+				chunkEnd = end
+			}
+			if chunkEnd > l.end {
+				chunkEnd = l.end
+			}
+			chunkBegin = begin
+			chunk = make([]byte, chunkEnd-chunkBegin)
+			_, err := l.reader.ReadAt(chunk, chunkBegin)
+			if err != nil && err != io.EOF {
+				return nil, fmt.Errorf("fail to read wordInfo %d: %s", wordId, err)
+			}
+		}
+		ret[wordId] = l.bufferToPosId(chunk, int(begin-chunkBegin))
+	}
+	return ret, nil
 }
 
 func (l *wordInfoList) wordIdToOffset(wordId int32) int {
@@ -196,7 +310,11 @@ func (lexicon *DoubleArrayLexicon) Lookup(text []byte, offset int) *DoubleArrayL
 func (lexicon *DoubleArrayLexicon) GetWordId(headword string, posId int16, readingForm string) int32 {
 	var wid int32
 	for ; wid < lexicon.wordInfos.wordSize; wid++ {
-		wi := lexicon.wordInfos.getWordInfo(wid)
+		wi, err := lexicon.GetWordInfo(wid)
+		if err != nil {
+			// a word which cannot be read is not the one
+			continue
+		}
 		if wi.Surface == headword &&
 			wi.PosId == posId &&
 			wi.ReadingForm == readingForm {
@@ -218,10 +336,25 @@ func (lexicon *DoubleArrayLexicon) GetCost(wordId int32) int16 {
 	return lexicon.wordParams.getCost(wordId)
 }
 
-func (lexicon *DoubleArrayLexicon) GetWordInfo(wordId int32) *WordInfo {
+// GetWordInfo returns the wordInfo of wordId. It fails only if the
+// wordInfo cannot be read from the storage by pread.
+func (lexicon *DoubleArrayLexicon) GetWordInfo(wordId int32) (*WordInfo, error) {
 	return lexicon.wordInfos.getWordInfo(wordId)
 }
 
+// GetPosId returns the part of speech of wordId. It fails as GetWordInfo
+// does.
+func (lexicon *DoubleArrayLexicon) GetPosId(wordId int32) (int16, error) {
+	return lexicon.wordInfos.getPosId(wordId)
+}
+
+// GetPosIds returns the parts of speech of all the words, indexed by the
+// word ids. It is faster than calling GetPosId for each word when the
+// wordInfos are read by pread.
+func (lexicon *DoubleArrayLexicon) GetPosIds() ([]int16, error) {
+	return lexicon.wordInfos.getPosIds()
+}
+
 func (lexicon *DoubleArrayLexicon) GetDictionaryId(wordId int32) int {
 	return 0
 }
@@ -241,7 +374,10 @@ func (lexicon *DoubleArrayLexicon) CalculateCost(cf CalculateCostFunc) error {
 		if lexicon.wordParams.getCost(wordId) != minint16 {
 			continue
 		}
-		wi := lexicon.wordInfos.getWordInfo(wordId)
+		wi, err := lexicon.wordInfos.getWordInfo(wordId)
+		if err != nil {
+			return err
+		}
 		cost, err := cf(wi.Surface)
 		if err != nil {
 			return err
@@ -302,8 +438,11 @@ func (lexicon *DoubleArrayLexicon) WriteWordInfos(writer io.Writer, offset int64
 	base := offset + offsetlen
 	position := base
 	for wordId := int32(0); wordId < lexicon.Size(); wordId++ {
-		wi := lexicon.GetWordInfo(wordId)
-		err := binary.Write(offsets, binary.LittleEndian, uint32(position))
+		wi, err := lexicon.GetWordInfo(wordId)
+		if err != nil {
+			return 0, offsets, err
+		}
+		err = binary.Write(offsets, binary.LittleEndian, uint32(position))
 		if err != nil {
 			return 0, offsets, err
 		}
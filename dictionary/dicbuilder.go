@@ -10,14 +10,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/emirpasic/gods/trees/redblacktree"
 	"github.com/msnoigrs/gosudachi/dartsclone"
 	"github.com/msnoigrs/gosudachi/internal/lnreader"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 )
 
 const (
@@ -26,6 +26,9 @@ const (
 	ArrayMaxLength       = 127
 	NumberOfColumns      = 18
 	BufferSize           = 1024 * 1024
+
+	parseProgressInterval = 100000
+	lexiconChunkSize      = 8192
 )
 
 type wordEntry struct {
@@ -98,6 +101,17 @@ func NewPosTableUser(base PosIdStore) *PosTableUser {
 	}
 }
 
+// lexiconChunk is a run of lines of the source which is parsed at once.
+type lexiconChunk struct {
+	data    []byte
+	ends    []int
+	numLine int // the line number of the first line
+	entries []*wordEntry
+	poss    [][]string
+	err     error
+	done    chan struct{}
+}
+
 type lexiconReader struct {
 	r           *bufio.Reader
 	rawBuffer   []byte
@@ -142,7 +156,32 @@ func (r *lexiconReader) readRecord(dst []string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return r.splitRecord(line, dst), nil
+}
+
+// readChunk reads at most size lines.
+func (r *lexiconReader) readChunk(size int) (*lexiconChunk, error) {
+	chunk := &lexiconChunk{
+		numLine: r.numLine + 1,
+	}
+	for len(chunk.ends) < size {
+		line, err := r.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunk.data = append(chunk.data, line...)
+		chunk.ends = append(chunk.ends, len(chunk.data))
+	}
+	if len(chunk.ends) == 0 {
+		return nil, io.EOF
+	}
+	return chunk, nil
+}
 
+func (r *lexiconReader) splitRecord(line []byte, dst []string) []string {
 	dst = dst[:0]
 
 	var i int
@@ -162,7 +201,7 @@ func (r *lexiconReader) readRecord(dst []string) ([]string, error) {
 		}
 	}
 
-	return dst, nil
+	return dst
 }
 
 func (r *lexiconReader) decode(s []byte) []byte {
@@ -215,7 +254,7 @@ func (r *lexiconReader) decode(s []byte) []byte {
 				if brace && ncount >= 0 {
 					i32, err := strconv.ParseInt(string(s[numstart:i-width]), 16, 32)
 					if i32 <= 0x10FFFF && err == nil {
-						r.fieldBuffer = append(r.fieldBuffer, []byte(string(i32))...)
+						r.fieldBuffer = append(r.fieldBuffer, []byte(string(rune(i32)))...)
 						last = i
 					}
 					brace = false
@@ -229,7 +268,7 @@ func (r *lexiconReader) decode(s []byte) []byte {
 					if ncount == 4 {
 						i32, err := strconv.ParseInt(string(s[numstart:i]), 16, 32)
 						if i32 <= 0x10FFFF && err == nil {
-							r.fieldBuffer = append(r.fieldBuffer, []byte(string(i32))...)
+							r.fieldBuffer = append(r.fieldBuffer, []byte(string(rune(i32)))...)
 							last = i
 						}
 						nmark = -1
@@ -262,6 +301,10 @@ type DictionaryBuilder struct {
 	systemLexicon    *DoubleArrayLexicon
 	writeStringF     writeStringFunc
 	stringLen        stringLenFunc
+	progressF        BuildProgressFunc
+	parallelism      int
+	stats            *BuildStats
+	phaseStart       time.Time
 }
 
 func NewDictionaryBuilder(position int64, systemLexicon *DoubleArrayLexicon, utf16string bool) *DictionaryBuilder {
@@ -283,6 +326,7 @@ func NewDictionaryBuilder(position int64, systemLexicon *DoubleArrayLexicon, utf
 		systemLexicon:    systemLexicon,
 		buffer:           bytes.NewBuffer([]byte{}),
 		position:         position,
+		stats:            newBuildStats(),
 	}
 	if utf16string {
 		ret.writeStringF = writeStringUtf16
@@ -294,98 +338,168 @@ func NewDictionaryBuilder(position int64, systemLexicon *DoubleArrayLexicon, utf
 	return ret
 }
 
+// SetProgressFunc sets the function called as the building goes on.
+func (dicbuilder *DictionaryBuilder) SetProgressFunc(f BuildProgressFunc) {
+	dicbuilder.progressF = f
+}
+
+// SetParallelism sets the number of goroutines which parse the source
+// in BuildLexicon. The result does not depend on n.
+func (dicbuilder *DictionaryBuilder) SetParallelism(n int) {
+	dicbuilder.parallelism = n
+}
+
+// Stats returns the statistics collected so far. The returned value is
+// updated by the subsequent calls of the builder.
+func (dicbuilder *DictionaryBuilder) Stats() *BuildStats {
+	return dicbuilder.stats
+}
+
+func (dicbuilder *DictionaryBuilder) progress(phase BuildPhase, state int, max int) {
+	if dicbuilder.progressF != nil {
+		dicbuilder.progressF(phase, state, max)
+	}
+}
+
+func (dicbuilder *DictionaryBuilder) beginPhase(phase BuildPhase, max int) {
+	dicbuilder.phaseStart = time.Now()
+	dicbuilder.progress(phase, 0, max)
+}
+
+func (dicbuilder *DictionaryBuilder) endPhase(phase BuildPhase, max int, size int64) {
+	dicbuilder.stats.Elapsed[phase] += time.Since(dicbuilder.phaseStart)
+	dicbuilder.stats.Sizes[phase] += size
+	dicbuilder.progress(phase, max, max)
+}
+
 func (dicbuilder *DictionaryBuilder) BuildLexicon(store PosIdStore, input io.Reader) error {
-	var recordBuf []string
+	start := time.Now()
+	defer func() {
+		dicbuilder.stats.Elapsed[PhaseParse] += time.Since(start)
+	}()
+
 	r := newLexiconReader(input)
+	if dicbuilder.parallelism > 1 {
+		return dicbuilder.buildLexiconParallel(store, r)
+	}
 	for {
-		cols, err := r.readRecord(recordBuf)
+		chunk, err := r.readChunk(lexiconChunkSize)
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return err
 		}
-		// parseLine
-		if len(cols) != NumberOfColumns {
-			return fmt.Errorf("invalid format at line: columns length must be %d: at line %d", NumberOfColumns, r.numLine)
+		dicbuilder.parseChunk(chunk)
+		if chunk.err != nil {
+			return chunk.err
 		}
+		dicbuilder.mergeChunk(store, chunk)
+	}
+	return nil
+}
 
-		if dicbuilder.stringLen(cols[0]) {
-			return fmt.Errorf("string is too long: column 0 at line %d", r.numLine)
-		}
-		if dicbuilder.stringLen(cols[4]) {
-			return fmt.Errorf("string is too long: column 4 at line %d", r.numLine)
-		}
-		if dicbuilder.stringLen(cols[11]) {
-			return fmt.Errorf("string is too long: column 11 at line %d", r.numLine)
-		}
-		if dicbuilder.stringLen(cols[12]) {
-			return fmt.Errorf("string is too long: column 12 at line %d", r.numLine)
+// buildLexiconParallel parses chunks of lines concurrently. The parsed
+// chunks are merged in the order they are read, so the word IDs, the part
+// of speech IDs and the reported errors are the same as BuildLexicon with
+// no parallelism.
+func (dicbuilder *DictionaryBuilder) buildLexiconParallel(store PosIdStore, r *lexiconReader) error {
+	jobs := make(chan *lexiconChunk, dicbuilder.parallelism)
+	results := make(chan *lexiconChunk, dicbuilder.parallelism*2)
+	quit := make(chan struct{})
+	// the goroutines must not read r or parse the chunks after returning
+	var wg sync.WaitGroup
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+
+	wg.Add(dicbuilder.parallelism + 1)
+	for i := 0; i < dicbuilder.parallelism; i++ {
+		go func() {
+			defer wg.Done()
+			for chunk := range jobs {
+				dicbuilder.parseChunk(chunk)
+				close(chunk.done)
+			}
+		}()
+	}
+
+	go func() {
+		defer wg.Done()
+		defer close(results)
+		defer close(jobs)
+		for {
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			chunk, err := r.readChunk(lexiconChunkSize)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				chunk = &lexiconChunk{
+					err:  err,
+					done: make(chan struct{}),
+				}
+				close(chunk.done)
+				select {
+				case results <- chunk:
+				case <-quit:
+				}
+				return
+			}
+			chunk.done = make(chan struct{})
+			select {
+			case results <- chunk:
+			case <-quit:
+				return
+			}
+			jobs <- chunk
 		}
+	}()
 
-		entry := &wordEntry{}
-
-		// headword for trie
-		if cols[1] != "-1" {
-			entry.Headword = cols[0]
+	for chunk := range results {
+		<-chunk.done
+		if chunk.err != nil {
+			return chunk.err
 		}
+		dicbuilder.mergeChunk(store, chunk)
+	}
+	return nil
+}
 
-		// left-id, right-id, cost
-		cols1, err := strconv.ParseInt(cols[1], 10, 16)
-		if err != nil {
-			return fmt.Errorf("%s: column 1 at line %d", err, r.numLine)
-		}
-		cols2, err := strconv.ParseInt(cols[2], 10, 16)
+func (dicbuilder *DictionaryBuilder) parseChunk(chunk *lexiconChunk) {
+	r := &lexiconReader{}
+	chunk.entries = make([]*wordEntry, 0, len(chunk.ends))
+	chunk.poss = make([][]string, 0, len(chunk.ends))
+	begin := 0
+	for i, end := range chunk.ends {
+		cols := r.splitRecord(chunk.data[begin:end], nil)
+		begin = end
+		entry, err := dicbuilder.parseEntry(cols, chunk.numLine+i)
 		if err != nil {
-			return fmt.Errorf("%s: column 2 at line %d", err, r.numLine)
-		}
-		cols3, err := strconv.ParseInt(cols[3], 10, 16)
-		if err != nil {
-			return fmt.Errorf("%s: column 3 at line %d", err, r.numLine)
-		}
-		entry.Parameters[0] = int16(cols1)
-		entry.Parameters[1] = int16(cols2)
-		entry.Parameters[2] = int16(cols3)
-
-		// part of speech
-		posId := store.GetPosId(cols[5], cols[6], cols[7], cols[8], cols[9], cols[10])
-
-		if strings.Count(cols[15], "/")+1 > ArrayMaxLength {
-			return fmt.Errorf("too many units: columns 15 at line %d", r.numLine)
-		}
-		if strings.Count(cols[16], "/")+1 > ArrayMaxLength {
-			return fmt.Errorf("too many units: columns 16 at line %d", r.numLine)
-		}
-		if strings.Count(cols[17], "/")+1 > ArrayMaxLength {
-			return fmt.Errorf("too many units: columns 17 at line %d", r.numLine)
-		}
-		if cols[14] == "A" && (cols[15] != "*" || cols[16] != "*") {
-			return fmt.Errorf("invalid splitting at line %d", r.numLine)
-		}
-		entry.AUnitSplitString = cols[15]
-		entry.BUnitSplitString = cols[16]
-		entry.WordStructureString = cols[17]
-
-		var dicFormWordId int32
-		if cols[13] == "*" {
-			dicFormWordId = -1
-		} else {
-			cols13, err := strconv.ParseInt(cols[13], 10, 32)
-			if err != nil {
-				return fmt.Errorf("%s: column 13 at line %d", err, r.numLine)
-			}
-			dicFormWordId = int32(cols13)
+			chunk.err = err
+			break
 		}
+		chunk.entries = append(chunk.entries, entry)
+		chunk.poss = append(chunk.poss, cols[5:11])
+	}
+	chunk.data = nil
+	chunk.ends = nil
+}
 
-		entry.WordInfo = &WordInfo{
-			Surface:              cols[4], // headword
-			HeadwordLength:       int16(len(cols[0])),
-			PosId:                posId,
-			NormalizedForm:       cols[12],      // normalizedForm
-			DictionaryFormWordId: dicFormWordId, // dictionaryFormWordId
-			DictionaryForm:       "",            // dummy
-			ReadingForm:          cols[11],      // readingForm
+func (dicbuilder *DictionaryBuilder) mergeChunk(store PosIdStore, chunk *lexiconChunk) {
+	for i, entry := range chunk.entries {
+		pos := chunk.poss[i]
+		posId := store.GetPosId(pos...)
+		entry.WordInfo.PosId = posId
+		if dicbuilder.stats.EntriesByPos[posId] == 0 {
+			dicbuilder.stats.Pos[posId] = pos
 		}
+		dicbuilder.stats.EntriesByPos[posId]++
 
 		if entry.Headword != "" {
 			// addToTrie
@@ -399,9 +513,94 @@ func (dicbuilder *DictionaryBuilder) BuildLexicon(store PosIdStore, input io.Rea
 			}
 		}
 		dicbuilder.wordEntries = append(dicbuilder.wordEntries, entry)
+		dicbuilder.stats.Entries = len(dicbuilder.wordEntries)
+		if dicbuilder.stats.Entries%parseProgressInterval == 0 {
+			dicbuilder.progress(PhaseParse, dicbuilder.stats.Entries, -1)
+		}
 	}
+}
 
-	return nil
+// parseEntry parses a line except the part of speech, which is resolved
+// by mergeChunk.
+func (dicbuilder *DictionaryBuilder) parseEntry(cols []string, numLine int) (*wordEntry, error) {
+	if len(cols) != NumberOfColumns {
+		return nil, fmt.Errorf("invalid format at line: columns length must be %d: at line %d", NumberOfColumns, numLine)
+	}
+
+	if dicbuilder.stringLen(cols[0]) {
+		return nil, fmt.Errorf("string is too long: column 0 at line %d", numLine)
+	}
+	if dicbuilder.stringLen(cols[4]) {
+		return nil, fmt.Errorf("string is too long: column 4 at line %d", numLine)
+	}
+	if dicbuilder.stringLen(cols[11]) {
+		return nil, fmt.Errorf("string is too long: column 11 at line %d", numLine)
+	}
+	if dicbuilder.stringLen(cols[12]) {
+		return nil, fmt.Errorf("string is too long: column 12 at line %d", numLine)
+	}
+
+	entry := &wordEntry{}
+
+	// headword for trie
+	if cols[1] != "-1" {
+		entry.Headword = cols[0]
+	}
+
+	// left-id, right-id, cost
+	cols1, err := strconv.ParseInt(cols[1], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%s: column 1 at line %d", err, numLine)
+	}
+	cols2, err := strconv.ParseInt(cols[2], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%s: column 2 at line %d", err, numLine)
+	}
+	cols3, err := strconv.ParseInt(cols[3], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%s: column 3 at line %d", err, numLine)
+	}
+	entry.Parameters[0] = int16(cols1)
+	entry.Parameters[1] = int16(cols2)
+	entry.Parameters[2] = int16(cols3)
+
+	if strings.Count(cols[15], "/")+1 > ArrayMaxLength {
+		return nil, fmt.Errorf("too many units: columns 15 at line %d", numLine)
+	}
+	if strings.Count(cols[16], "/")+1 > ArrayMaxLength {
+		return nil, fmt.Errorf("too many units: columns 16 at line %d", numLine)
+	}
+	if strings.Count(cols[17], "/")+1 > ArrayMaxLength {
+		return nil, fmt.Errorf("too many units: columns 17 at line %d", numLine)
+	}
+	if cols[14] == "A" && (cols[15] != "*" || cols[16] != "*") {
+		return nil, fmt.Errorf("invalid splitting at line %d", numLine)
+	}
+	entry.AUnitSplitString = cols[15]
+	entry.BUnitSplitString = cols[16]
+	entry.WordStructureString = cols[17]
+
+	var dicFormWordId int32
+	if cols[13] == "*" {
+		dicFormWordId = -1
+	} else {
+		cols13, err := strconv.ParseInt(cols[13], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s: column 13 at line %d", err, numLine)
+		}
+		dicFormWordId = int32(cols13)
+	}
+
+	entry.WordInfo = &WordInfo{
+		Surface:              cols[4], // headword
+		HeadwordLength:       int16(len(cols[0])),
+		NormalizedForm:       cols[12],      // normalizedForm
+		DictionaryFormWordId: dicFormWordId, // dictionaryFormWordId
+		DictionaryForm:       "",            // dummy
+		ReadingForm:          cols[11],      // readingForm
+	}
+
+	return entry, nil
 }
 
 func writeStringLength(buffer *bytes.Buffer, length int16) error {
@@ -477,7 +676,7 @@ func (dicbuilder *DictionaryBuilder) writeIntArray(a []int32) error {
 func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.Reader, writer io.Writer) error {
 	bwriter := bufio.NewWriter(writer)
 
-	fmt.Fprint(os.Stderr, "writing the POS table...")
+	dicbuilder.beginPhase(PhasePosTable, 1)
 
 	err := dicbuilder.convertPOSTable(postable)
 	if err != nil {
@@ -488,8 +687,7 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 		return err
 	}
 	dicbuilder.position += n
-	p := message.NewPrinter(language.English)
-	p.Fprintf(os.Stderr, " %d bytes\n", n)
+	dicbuilder.endPhase(PhasePosTable, 1, n)
 	dicbuilder.buffer.Reset()
 
 	// convertMatrix
@@ -499,8 +697,6 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 		return fmt.Errorf("invalid format at line %d", r.NumLine)
 	}
 
-	fmt.Fprint(os.Stderr, "writing the connection matrix...")
-
 	lr := strings.Fields(string(header))
 	if len(lr) < 2 {
 		return fmt.Errorf("invalid format at line %d", r.NumLine)
@@ -522,6 +718,13 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 		return fmt.Errorf("%s: invalid format at line %d", err, r.NumLine)
 	}
 
+	matrixSize := int(leftSize * rightSize)
+	if matrixSize < 1 {
+		matrixSize = 1
+	}
+	step := matrixSize/10 + 1
+	dicbuilder.beginPhase(PhaseMatrix, matrixSize)
+
 	n, err = dicbuilder.buffer.WriteTo(bwriter)
 	if err != nil {
 		return err
@@ -532,7 +735,7 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 	buflen := 2 * leftSize * rightSize
 	matrix := make([]byte, buflen, buflen)
 
-	for {
+	for numEntries := 0; ; {
 		line, err := r.ReadLine()
 		if err == io.EOF {
 			break
@@ -561,6 +764,10 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 			return fmt.Errorf("%s: invalid format at line %d", err, r.NumLine)
 		}
 		binary.LittleEndian.PutUint16(matrix[2*(left+leftSize*right):], uint16(cost))
+		numEntries++
+		if numEntries%step == 0 && numEntries < matrixSize {
+			dicbuilder.progress(PhaseMatrix, numEntries, matrixSize)
+		}
 	}
 
 	nm, err := bwriter.Write(matrix)
@@ -568,7 +775,7 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 		return err
 	}
 	dicbuilder.position += int64(nm)
-	p.Fprintf(os.Stderr, " %d bytes\n", nm+4)
+	dicbuilder.endPhase(PhaseMatrix, matrixSize, int64(nm)+4)
 
 	err = bwriter.Flush()
 	if err != nil {
@@ -581,7 +788,7 @@ func (dicbuilder *DictionaryBuilder) WriteGrammar(postable *PosTable, input io.R
 func (dicbuilder *DictionaryBuilder) WriteGrammarUser(postable *PosTable, writer io.Writer) error {
 	bwriter := bufio.NewWriter(writer)
 
-	fmt.Fprint(os.Stderr, "writing the POS table...")
+	dicbuilder.beginPhase(PhasePosTable, 1)
 
 	err := dicbuilder.convertPOSTable(postable)
 	if err != nil {
@@ -592,11 +799,10 @@ func (dicbuilder *DictionaryBuilder) WriteGrammarUser(postable *PosTable, writer
 		return err
 	}
 	dicbuilder.position += n
-	p := message.NewPrinter(language.English)
-	p.Fprintf(os.Stderr, " %d bytes\n", n)
+	dicbuilder.endPhase(PhasePosTable, 1, n)
 	dicbuilder.buffer.Reset()
 
-	fmt.Fprint(os.Stderr, "writing the connection matrix...")
+	dicbuilder.beginPhase(PhaseMatrix, 1)
 
 	err = binary.Write(dicbuilder.buffer, binary.LittleEndian, uint16(0))
 	if err != nil {
@@ -611,8 +817,8 @@ func (dicbuilder *DictionaryBuilder) WriteGrammarUser(postable *PosTable, writer
 	if err != nil {
 		return err
 	}
-	dicbuilder.position += 4
-	fmt.Fprint(os.Stderr, " 4 bytes\n")
+	dicbuilder.position += n
+	dicbuilder.endPhase(PhaseMatrix, 1, n)
 	dicbuilder.buffer.Reset()
 
 	err = bwriter.Flush()
@@ -676,19 +882,18 @@ func (dicbuilder *DictionaryBuilder) WriteLexicon(writer io.WriteSeeker, store P
 		}
 	}
 
-	fmt.Fprint(os.Stderr, "building the trie")
+	dicbuilder.beginPhase(PhaseTrie, size+1)
 
 	err := trie.Build(keys, values, func(state int, max int) {
-		if state%((max/10)+1) == 0 {
-			fmt.Fprint(os.Stderr, ".")
+		if state < max && state%((max/10)+1) == 0 {
+			dicbuilder.progress(PhaseTrie, state, max)
 		}
 	})
 	if err != nil {
 		return err
 	}
-	fmt.Fprintln(os.Stderr, "done")
+	dicbuilder.stats.TrieSize = trie.Length()
 
-	fmt.Fprint(os.Stderr, "writing the trie...")
 	dicbuilder.buffer.Reset()
 
 	err = binary.Write(dicbuilder.buffer, binary.LittleEndian, uint32(trie.Length()))
@@ -707,10 +912,9 @@ func (dicbuilder *DictionaryBuilder) WriteLexicon(writer io.WriteSeeker, store P
 		return err
 	}
 	dicbuilder.position += int64(nn)
-	p := message.NewPrinter(language.English)
-	p.Fprintf(os.Stderr, " %d bytes\n", nn+4)
+	dicbuilder.endPhase(PhaseTrie, size+1, int64(nn)+4)
 
-	fmt.Fprint(os.Stderr, "writing the word-ID table...")
+	dicbuilder.beginPhase(PhaseWordIdTable, 1)
 	err = binary.Write(dicbuilder.buffer, binary.LittleEndian, uint32(position))
 	if err != nil {
 		return err
@@ -727,14 +931,19 @@ func (dicbuilder *DictionaryBuilder) WriteLexicon(writer io.WriteSeeker, store P
 		return err
 	}
 	dicbuilder.position += n
-	p.Fprintf(os.Stderr, " %d bytes\n", n+4)
+	dicbuilder.endPhase(PhaseWordIdTable, 1, n+4)
 
-	fmt.Fprint(os.Stderr, "writing the word parameters...")
+	numEntries := len(dicbuilder.wordEntries)
+	step := numEntries/10 + 1
+	dicbuilder.beginPhase(PhaseWordParameters, numEntries+1)
 	err = binary.Write(dicbuilder.buffer, binary.LittleEndian, uint32(len(dicbuilder.wordEntries)))
 	if err != nil {
 		return err
 	}
-	for _, entry := range dicbuilder.wordEntries {
+	for i, entry := range dicbuilder.wordEntries {
+		if i%step == 0 {
+			dicbuilder.progress(PhaseWordParameters, i+1, numEntries+1)
+		}
 		err = binary.Write(dicbuilder.buffer, binary.LittleEndian, uint16(entry.Parameters[0]))
 		if err != nil {
 			return err
@@ -754,7 +963,7 @@ func (dicbuilder *DictionaryBuilder) WriteLexicon(writer io.WriteSeeker, store P
 		dicbuilder.position += n
 		dicbuilder.buffer.Reset()
 	}
-	p.Fprintf(os.Stderr, " %d bytes\n", len(dicbuilder.wordEntries)*6+4)
+	dicbuilder.endPhase(PhaseWordParameters, numEntries+1, int64(numEntries)*6+4)
 
 	err = bwriter.Flush()
 	if err != nil {
@@ -779,10 +988,15 @@ func (dicbuilder *DictionaryBuilder) writeWordInfo(writer io.WriteSeeker, store
 
 	offsets := bytes.NewBuffer(make([]byte, 0, offsetslen))
 
-	fmt.Fprint(os.Stderr, "writing the wordInfos...")
+	numEntries := len(dicbuilder.wordEntries)
+	step := numEntries/10 + 1
+	dicbuilder.beginPhase(PhaseWordInfo, numEntries+1)
 	base := dicbuilder.position + offsetslen
 	position := base
-	for _, we := range dicbuilder.wordEntries {
+	for i, we := range dicbuilder.wordEntries {
+		if i%step == 0 {
+			dicbuilder.progress(PhaseWordInfo, i+1, numEntries+1)
+		}
 		wi := we.WordInfo
 		err = binary.Write(offsets, binary.LittleEndian, uint32(position))
 		if err != nil {
@@ -851,14 +1065,13 @@ func (dicbuilder *DictionaryBuilder) writeWordInfo(writer io.WriteSeeker, store
 		dicbuilder.buffer.Reset()
 		position += n
 	}
-	p := message.NewPrinter(language.English)
-	p.Fprintf(os.Stderr, " %d bytes\n", position-base)
 	err = bwriter.Flush()
 	if err != nil {
 		return err
 	}
+	dicbuilder.endPhase(PhaseWordInfo, numEntries+1, position-base)
 
-	fmt.Fprint(os.Stderr, "writing wordInfo offsets...")
+	dicbuilder.beginPhase(PhaseWordInfoOffsets, 1)
 	_, err = writer.Seek(dicbuilder.position, io.SeekStart)
 	if err != nil {
 		return err
@@ -868,11 +1081,11 @@ func (dicbuilder *DictionaryBuilder) writeWordInfo(writer io.WriteSeeker, store
 	if err != nil {
 		return err
 	}
-	p.Fprintf(os.Stderr, " %d bytes\n", n)
 	err = bwriter.Flush()
 	if err != nil {
 		return err
 	}
+	dicbuilder.endPhase(PhaseWordInfoOffsets, 1, n)
 
 	return nil
 }
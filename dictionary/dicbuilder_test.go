@@ -0,0 +1,134 @@
+package dictionary
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func generateLexicon(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "語%d,%d,%d,%d,語%d,名詞,普通名詞,一般,%d,*,*,ゴ,語%d,*,A,*,*,*\n",
+			i%997, i%7, i%11, i%5000, i%997, i%13, i)
+	}
+	return b.String()
+}
+
+func buildSystemDictionary(t *testing.T, lexicon string, parallelism int) []byte {
+	f, err := ioutil.TempFile("", "dicbuilder")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	hb, err := NewDictionaryHeader(SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	dicbuilder := NewDictionaryBuilder(int64(len(hb)), nil, false)
+	dicbuilder.SetParallelism(parallelism)
+	store := NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(lexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader("1 1\n0 0 0\n"), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return b
+}
+
+func TestBuildLexiconParallel(t *testing.T) {
+	lexicon := generateLexicon(3*lexiconChunkSize + 100)
+
+	want := buildSystemDictionary(t, lexicon, 1)
+	for _, parallelism := range []int{2, 8} {
+		got := buildSystemDictionary(t, lexicon, parallelism)
+		if !bytes.Equal(want, got) {
+			t.Errorf("parallelism %d: the dictionary differs from the sequential build", parallelism)
+		}
+	}
+}
+
+func TestBuildLexiconParallelError(t *testing.T) {
+	lexicon := generateLexicon(2*lexiconChunkSize) + "invalid\n" + generateLexicon(lexiconChunkSize)
+	want := fmt.Sprintf("at line %d", 2*lexiconChunkSize+1)
+
+	for _, parallelism := range []int{1, 4} {
+		dicbuilder := NewDictionaryBuilder(0, nil, false)
+		dicbuilder.SetParallelism(parallelism)
+		err := dicbuilder.BuildLexicon(NewPosTable(), strings.NewReader(lexicon))
+		if err == nil {
+			t.Fatalf("parallelism %d: error is expected", parallelism)
+		}
+		if !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("parallelism %d: want = ...%s, got = %s", parallelism, want, err)
+		}
+	}
+
+	// the input is not read after BuildLexicon returns
+	input := &closableReader{r: strings.NewReader("invalid\n" + generateLexicon(20*lexiconChunkSize))}
+	dicbuilder := NewDictionaryBuilder(0, nil, false)
+	dicbuilder.SetParallelism(4)
+	err := dicbuilder.BuildLexicon(NewPosTable(), input)
+	if err == nil {
+		t.Fatal("error is expected")
+	}
+	input.close()
+	time.Sleep(10 * time.Millisecond)
+	if input.readAfterClose() {
+		t.Error("the input is read after BuildLexicon returns")
+	}
+}
+
+type closableReader struct {
+	mu        sync.Mutex
+	r         io.Reader
+	closed    bool
+	afterRead bool
+}
+
+func (r *closableReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		r.afterRead = true
+		return 0, errors.New("read after close")
+	}
+	return r.r.Read(p)
+}
+
+func (r *closableReader) close() {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+}
+
+func (r *closableReader) readAfterClose() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.afterRead
+}
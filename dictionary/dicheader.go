@@ -15,6 +15,7 @@ type DictionaryHeader struct {
 	Version     uint64
 	CreateTime  int64
 	Description string
+	Metadata    *DictionaryMetadata
 }
 
 func NewDictionaryHeader(version uint64, createTime int64, description string) *DictionaryHeader {
@@ -48,7 +49,11 @@ func ParseDictionaryHeader(input []byte, offset int) *DictionaryHeader {
 func (dh *DictionaryHeader) ToBytes() ([]byte, error) {
 	desc := []byte(dh.Description)
 	if len(desc) > DescriptionSize {
-		return nil, errors.New("description is too long")
+		if dh.Metadata == nil {
+			return nil, errors.New("description is too long")
+		}
+		// the whole description goes to the metadata section
+		desc = []byte(truncateDescription(dh.Description))
 	}
 
 	buf := bytes.NewBuffer(make([]byte, 0, HeaderStorageSize))
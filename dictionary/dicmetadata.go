@@ -0,0 +1,333 @@
+package dictionary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"unicode/utf8"
+)
+
+// The extended metadata section is appended to the end of a dictionary
+// file, after all the sections which readers access by offset, so readers
+// which do not know it keep working. It is laid out as
+//
+//	uint32 format version
+//	uint32 number of entries
+//	entries: uint32 key length, key, uint32 value length, value
+//	uint64 length of the above
+//	8 bytes magic
+//
+// A reader reads the entries and ignores the rest of the section, so that
+// later versions can add fields after the entries.
+const (
+	MetadataVersion = 1
+
+	metadataTrailerSize = 8 + 8
+
+	modulePath = "github.com/msnoigrs/gosudachi"
+)
+
+var metadataMagic = []byte("SDICMETA")
+
+// Well-known metadata keys.
+const (
+	MetadataDescription  = "description"
+	MetadataSourceCommit = "source-commit"
+	MetadataLicense      = "license"
+	MetadataBuildTool    = "build-tool"
+	MetadataEncoding     = "encoding"
+)
+
+type MetadataEntry struct {
+	Key   string
+	Value string
+}
+
+// DictionaryMetadata is a list of key/value pairs which keeps the order
+// in which they are added.
+type DictionaryMetadata struct {
+	Version uint32
+	Entries []MetadataEntry
+}
+
+func NewDictionaryMetadata() *DictionaryMetadata {
+	return &DictionaryMetadata{
+		Version: MetadataVersion,
+	}
+}
+
+func (m *DictionaryMetadata) Get(key string) (string, bool) {
+	for _, e := range m.Entries {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// Put sets the value of key, replacing the existing one.
+func (m *DictionaryMetadata) Put(key string, value string) {
+	for i, e := range m.Entries {
+		if e.Key == key {
+			m.Entries[i].Value = value
+			return
+		}
+	}
+	m.Entries = append(m.Entries, MetadataEntry{Key: key, Value: value})
+}
+
+// PutDefault sets the value of key unless it is already set.
+func (m *DictionaryMetadata) PutDefault(key string, value string) {
+	if _, ok := m.Get(key); !ok {
+		m.Put(key, value)
+	}
+}
+
+// String and Set make DictionaryMetadata usable as a flag.Value which
+// accepts "key=value".
+func (m *DictionaryMetadata) String() string {
+	if m == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		pairs = append(pairs, e.Key+"="+e.Value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m *DictionaryMetadata) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return fmt.Errorf("metadata must be key=value: %s", s)
+	}
+	m.Put(s[:i], s[i+1:])
+	return nil
+}
+
+// ToBytes returns the metadata section including the trailer.
+func (m *DictionaryMetadata) ToBytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := binary.Write(buf, binary.LittleEndian, m.Version)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Write(buf, binary.LittleEndian, uint32(len(m.Entries)))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range m.Entries {
+		err = writeMetadataString(buf, e.Key)
+		if err != nil {
+			return nil, err
+		}
+		err = writeMetadataString(buf, e.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = binary.Write(buf, binary.LittleEndian, uint64(buf.Len()))
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(metadataMagic)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMetadataString(buf *bytes.Buffer, s string) error {
+	err := binary.Write(buf, binary.LittleEndian, uint32(len(s)))
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteString(s)
+	return err
+}
+
+// ReadDictionaryMetadata reads the metadata section at the end of input,
+// which is the whole dictionary file. It returns nil if there is none.
+func ReadDictionaryMetadata(input []byte) (*DictionaryMetadata, error) {
+	return ReadDictionaryMetadataAt(bytes.NewReader(input), int64(len(input)))
+}
+
+// ReadDictionaryMetadataAt is the same as ReadDictionaryMetadata except
+// that the dictionary file of size bytes is read from r.
+func ReadDictionaryMetadataAt(r io.ReaderAt, size int64) (*DictionaryMetadata, error) {
+	length, err := readMetadataLength(r, size)
+	if err != nil || length == 0 {
+		return nil, err
+	}
+	section := make([]byte, int(length))
+	_, err = r.ReadAt(section, size-metadataTrailerSize-int64(length))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	offset, version := bufferToUint32(section, 0)
+	if version == 0 {
+		return nil, errors.New("invalid metadata version")
+	}
+	offset, entrySize := bufferToUint32(section, offset)
+
+	m := &DictionaryMetadata{
+		Version: version,
+	}
+	for i := uint32(0); i < entrySize; i++ {
+		var key, value string
+		var ok bool
+		offset, key, ok = readMetadataString(section, offset)
+		if !ok {
+			return nil, errors.New("invalid metadata section")
+		}
+		offset, value, ok = readMetadataString(section, offset)
+		if !ok {
+			return nil, errors.New("invalid metadata section")
+		}
+		m.Entries = append(m.Entries, MetadataEntry{Key: key, Value: value})
+	}
+	return m, nil
+}
+
+// readMetadataLength returns the length of the metadata section without
+// the trailer, or 0 if the dictionary file of size bytes has no metadata.
+func readMetadataLength(r io.ReaderAt, size int64) (int64, error) {
+	if size < HeaderStorageSize+metadataTrailerSize {
+		return 0, nil
+	}
+	trailer := make([]byte, metadataTrailerSize)
+	_, err := r.ReadAt(trailer, size-metadataTrailerSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if !bytes.Equal(trailer[8:], metadataMagic) {
+		return 0, nil
+	}
+	_, length := bufferToUint64(trailer, 0)
+	if length < 8 || length > uint64(size-HeaderStorageSize-metadataTrailerSize) {
+		return 0, errors.New("invalid metadata section")
+	}
+	return int64(length), nil
+}
+
+// metadataOffset returns the offset of the metadata section, which is the
+// end of the lexicon, of the dictionary file of size bytes.
+func metadataOffset(r io.ReaderAt, size int64) (int64, error) {
+	length, err := readMetadataLength(r, size)
+	if err != nil || length == 0 {
+		return size, err
+	}
+	return size - metadataTrailerSize - length, nil
+}
+
+func readMetadataString(section []byte, offset int) (int, string, bool) {
+	if offset+4 > len(section) {
+		return offset, "", false
+	}
+	offset, length := bufferToUint32(section, offset)
+	if uint64(offset)+uint64(length) > uint64(len(section)) {
+		return offset, "", false
+	}
+	end := offset + int(length)
+	return end, string(section[offset:end]), true
+}
+
+// truncateDescription cuts s to at most DescriptionSize bytes without
+// breaking a UTF-8 sequence.
+func truncateDescription(s string) string {
+	if len(s) <= DescriptionSize {
+		return s
+	}
+	i := DescriptionSize
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i]
+}
+
+// ReadMetadata sets the metadata section of input, which is the whole
+// dictionary file, to dh. The description is replaced with the one in the
+// metadata if the header has a truncated copy of it.
+func (dh *DictionaryHeader) ReadMetadata(input []byte) error {
+	return dh.ReadMetadataAt(bytes.NewReader(input), int64(len(input)))
+}
+
+// ReadMetadataAt is the same as ReadMetadata except that the dictionary
+// file of size bytes is read from r.
+func (dh *DictionaryHeader) ReadMetadataAt(r io.ReaderAt, size int64) error {
+	m, err := ReadDictionaryMetadataAt(r, size)
+	if err != nil {
+		return err
+	}
+	dh.Metadata = m
+	if m != nil {
+		desc, ok := m.Get(MetadataDescription)
+		if ok && len(desc) > len(dh.Description) && strings.HasPrefix(desc, dh.Description) {
+			dh.Description = desc
+		}
+	}
+	return nil
+}
+
+// MetadataToBytes returns the metadata section of dh, or nil if dh has no
+// metadata. A description too long for the header is stored in full.
+func (dh *DictionaryHeader) MetadataToBytes() ([]byte, error) {
+	if dh.Metadata == nil {
+		return nil, nil
+	}
+	m := dh.Metadata
+	if len(dh.Description) > DescriptionSize {
+		if _, ok := m.Get(MetadataDescription); !ok {
+			m = &DictionaryMetadata{
+				Version: m.Version,
+				Entries: append([]MetadataEntry{{Key: MetadataDescription, Value: dh.Description}}, m.Entries...),
+			}
+		}
+	}
+	return m.ToBytes()
+}
+
+// WriteMetadataTo appends the metadata section to the end of writer.
+// It writes nothing if dh has no metadata.
+func (dh *DictionaryHeader) WriteMetadataTo(writer io.WriteSeeker) (int, error) {
+	b, err := dh.MetadataToBytes()
+	if err != nil || b == nil {
+		return 0, err
+	}
+	_, err = writer.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	return writer.Write(b)
+}
+
+// BuildToolVersion returns the value of MetadataBuildTool for command.
+func BuildToolVersion(command string) string {
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath {
+			version = info.Main.Version
+		} else {
+			for _, dep := range info.Deps {
+				if dep.Path == modulePath {
+					version = dep.Version
+					break
+				}
+			}
+		}
+	}
+	return "gosudachi " + command + " " + version
+}
+
+// StringEncoding returns the value of MetadataEncoding.
+func StringEncoding(utf16string bool) string {
+	if utf16string {
+		return "UTF-16"
+	}
+	return "UTF-8"
+}
@@ -0,0 +1,71 @@
+package dictionary
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDictionaryMetadata(t *testing.T) {
+	description := strings.Repeat("あ", 100)
+
+	dh := NewDictionaryHeader(SystemDictVersion, 1234, description)
+	_, err := dh.ToBytes()
+	if err == nil {
+		t.Fatal("description is too long without metadata")
+	}
+
+	dh.Metadata = NewDictionaryMetadata()
+	err = dh.Metadata.Set("license=Apache-2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dh.Metadata.Put(MetadataSourceCommit, "abc=def")
+	hb, err := dh.ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(hb) != HeaderStorageSize {
+		t.Fatalf("invalid header size: %d", len(hb))
+	}
+	mb, err := dh.MetadataToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	body := make([]byte, 100)
+	input := append(append(hb, body...), mb...)
+
+	parsed := ParseDictionaryHeader(input, 0)
+	if want := strings.Repeat("あ", 85); parsed.Description != want {
+		t.Errorf("invalid truncated description: %s", parsed.Description)
+	}
+	err = parsed.ReadMetadata(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if parsed.Description != description {
+		t.Errorf("invalid description: %s", parsed.Description)
+	}
+	if parsed.Metadata.Version != MetadataVersion {
+		t.Errorf("invalid version: %d", parsed.Metadata.Version)
+	}
+	want := []MetadataEntry{
+		{MetadataDescription, description},
+		{MetadataLicense, "Apache-2.0"},
+		{MetadataSourceCommit, "abc=def"},
+	}
+	if len(parsed.Metadata.Entries) != len(want) {
+		t.Fatalf("invalid entries: %v", parsed.Metadata.Entries)
+	}
+	for i, e := range want {
+		if parsed.Metadata.Entries[i] != e {
+			t.Errorf("entry %d: want = %v, got = %v", i, e, parsed.Metadata.Entries[i])
+		}
+	}
+
+	old := append(append([]byte{}, hb...), body...)
+	m, err := ReadDictionaryMetadata(old)
+	if m != nil || err != nil {
+		t.Errorf("no metadata is expected: %v, %v", m, err)
+	}
+}
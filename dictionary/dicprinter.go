@@ -8,8 +8,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/msnoigrs/gosudachi/internal/mmap"
 )
 
 func PrintDictionary(filename string, utf16string bool, systemDict *BinaryDictionary, output io.Writer) error {
@@ -32,7 +30,7 @@ func PrintDictionary(filename string, utf16string bool, systemDict *BinaryDictio
 	}
 
 	possize := grammar.GetPartOfSpeechSize()
-	posStrings := make([]string, possize, possize)
+	posStrings := make([]string, 0, possize)
 	for pid := 0; pid < possize; pid++ {
 		posStrings = append(posStrings, strings.Join(grammar.GetPartOfSpeechString(int16(pid)), ","))
 	}
@@ -42,7 +40,10 @@ func PrintDictionary(filename string, utf16string bool, systemDict *BinaryDictio
 		leftId := lexicon.GetLeftId(wordId)
 		rightId := lexicon.GetRightId(wordId)
 		cost := lexicon.GetCost(wordId)
-		wi := lexicon.GetWordInfo(wordId)
+		wi, err := lexicon.GetWordInfo(wordId)
+		if err != nil {
+			return err
+		}
 
 		unitType := getUnitType(wi)
 
@@ -105,13 +106,17 @@ func PrintHeader(dictfile string, output io.Writer) error {
 		return err
 	}
 
-	bytebuffer, err := mmap.Mmap(dictfd, false, 0, finfo.Size())
+	bytebuffer := make([]byte, HeaderStorageSize)
+	_, err = io.ReadFull(dictfd, bytebuffer)
 	if err != nil {
 		return err
 	}
-	defer mmap.Munmap(bytebuffer)
 
 	dh := ParseDictionaryHeader(bytebuffer, 0)
+	err = dh.ReadMetadataAt(dictfd, finfo.Size())
+	if err != nil {
+		return err
+	}
 
 	fmt.Fprintf(output, "filename: %s\n", dictfile)
 
@@ -129,6 +134,15 @@ func PrintHeader(dictfile string, output io.Writer) error {
 	zone, _ := ctime.Zone()
 	fmt.Fprintf(output, "createTime: %s[%s]\n", ctime.Format(time.RFC3339), zone)
 	fmt.Fprintf(output, "description: %s\n", dh.Description)
+	if dh.Metadata != nil {
+		fmt.Fprintf(output, "metadata: version %d\n", dh.Metadata.Version)
+		for _, e := range dh.Metadata.Entries {
+			if e.Key == MetadataDescription && e.Value == dh.Description {
+				continue
+			}
+			fmt.Fprintf(output, "  %s: %s\n", e.Key, e.Value)
+		}
+	}
 
 	return nil
 }
@@ -8,7 +8,8 @@ import (
 )
 
 const (
-	posDepth            = 6
+	// PosDepth is the number of the levels of a part of speech.
+	PosDepth            = 6
 	InhibitedConnection = math.MaxInt16
 )
 
@@ -42,8 +43,8 @@ func NewGrammar(bytebuffer []byte, offset int, utf16string bool) *Grammar {
 	posLeni := int(posLen)
 	posList := make([][]string, posLeni, posLeni)
 	for i := 0; i < posLeni; i++ {
-		pos := make([]string, posDepth, posDepth)
-		for j := 0; j < posDepth; j++ {
+		pos := make([]string, PosDepth, PosDepth)
+		for j := 0; j < PosDepth; j++ {
 			offset, pos[j] = bufferToStringF(bytebuffer, offset)
 		}
 		posList[i] = pos
@@ -75,6 +76,18 @@ func (g *Grammar) GetPartOfSpeechSize() int {
 	return len(g.posList)
 }
 
+// GetLeftIdSize returns the number of the ids given to GetConnectCost as
+// leftId, which are the right ids of the preceding words.
+func (g *Grammar) GetLeftIdSize() int16 {
+	return g.leftIdSize
+}
+
+// GetRightIdSize returns the number of the ids given to GetConnectCost as
+// rightId, which are the left ids of the following words.
+func (g *Grammar) GetRightIdSize() int16 {
+	return g.rightIdSize
+}
+
 func (g *Grammar) GetPartOfSpeechString(posId int16) []string {
 	return g.posList[posId]
 }
@@ -82,7 +95,7 @@ func (g *Grammar) GetPartOfSpeechString(posId int16) []string {
 func (g *Grammar) GetPartOfSpeechId(pos []string) int16 {
 L:
 	for i, p := range g.posList {
-		for j := 0; j < posDepth; j++ {
+		for j := 0; j < PosDepth; j++ {
 			if p[j] != pos[j] {
 				continue L
 			}
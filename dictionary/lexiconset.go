@@ -58,10 +58,15 @@ func (s *LexiconSet) GetCost(wordId int32) int16 {
 	return s.lexicons[dictId].GetCost(wordId)
 }
 
-func (s *LexiconSet) GetWordInfo(wordId int32) *WordInfo {
+// GetWordInfo returns the wordInfo of wordId. It fails only if the
+// wordInfo cannot be read from the storage by pread.
+func (s *LexiconSet) GetWordInfo(wordId int32) (*WordInfo, error) {
 	dictId := int(uint32(wordId) >> 28)
 	wordId = int32(uint32(wordId) & 0xfffffff)
-	wi := s.lexicons[dictId].GetWordInfo(wordId)
+	wi, err := s.lexicons[dictId].GetWordInfo(wordId)
+	if err != nil {
+		return nil, err
+	}
 	if dictId > 0 && int32(wi.PosId) >= s.posOffsets[1] {
 		// user defined part-of-speech
 		wi.PosId = int16(int32(wi.PosId) - s.posOffsets[1] + s.posOffsets[dictId])
@@ -69,7 +74,41 @@ func (s *LexiconSet) GetWordInfo(wordId int32) *WordInfo {
 	s.convertSplit(wi.AUnitSplit, dictId)
 	s.convertSplit(wi.BUnitSplit, dictId)
 	s.convertSplit(wi.WordStructure, dictId)
-	return wi
+	return wi, nil
+}
+
+// GetPosId returns the part of speech of wordId. It fails as GetWordInfo
+// does.
+func (s *LexiconSet) GetPosId(wordId int32) (int16, error) {
+	dictId := int(uint32(wordId) >> 28)
+	wordId = int32(uint32(wordId) & 0xfffffff)
+	posId, err := s.lexicons[dictId].GetPosId(wordId)
+	if err != nil {
+		return 0, err
+	}
+	if dictId > 0 && int32(posId) >= s.posOffsets[1] {
+		// user defined part-of-speech
+		posId = int16(int32(posId) - s.posOffsets[1] + s.posOffsets[dictId])
+	}
+	return posId, nil
+}
+
+// GetPosIds returns the parts of speech of the words of the dictId-th
+// lexicon, indexed by the word ids in the lexicon.
+func (s *LexiconSet) GetPosIds(dictId int) ([]int16, error) {
+	posIds, err := s.lexicons[dictId].GetPosIds()
+	if err != nil {
+		return nil, err
+	}
+	if dictId > 0 {
+		for i, posId := range posIds {
+			if int32(posId) >= s.posOffsets[1] {
+				// user defined part-of-speech
+				posIds[i] = int16(int32(posId) - s.posOffsets[1] + s.posOffsets[dictId])
+			}
+		}
+	}
+	return posIds, nil
 }
 
 func (s *LexiconSet) GetDictionaryId(wordId int32) int {
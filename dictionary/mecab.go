@@ -0,0 +1,360 @@
+package dictionary
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi/internal/lnreader"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MeCabColumns tells where the features used by the Sudachi lexicon are
+// found in a row of a MeCab dictionary source (CSV) file. The surface and
+// the left-id, right-id and cost are always the first four columns.
+type MeCabColumns struct {
+	Pos            int // the first of the six part-of-speech columns
+	DictionaryForm int
+	NormalizedForm int
+	ReadingForm    int
+}
+
+var (
+	// IpadicColumns is the layout of IPADIC and NAIST-jdic.
+	IpadicColumns = &MeCabColumns{
+		Pos:            4,
+		DictionaryForm: 10,
+		NormalizedForm: 10,
+		ReadingForm:    11,
+	}
+	// UnidicColumns is the layout of unidic-mecab.
+	UnidicColumns = &MeCabColumns{
+		Pos:            4,
+		DictionaryForm: 14,
+		NormalizedForm: 11,
+		ReadingForm:    13,
+	}
+)
+
+func GetMeCabColumns(format string) (*MeCabColumns, error) {
+	switch strings.ToLower(format) {
+	case "ipadic", "naist-jdic":
+		return IpadicColumns, nil
+	case "unidic":
+		return UnidicColumns, nil
+	}
+	return nil, fmt.Errorf("%s is unknown MeCab dictionary format", format)
+}
+
+type mecabPosMapping struct {
+	from []string
+	to   []string
+}
+
+// MeCabPosMap maps MeCab part-of-speech features to Sudachi ones.
+// The longest matching prefix of the MeCab features wins.
+type MeCabPosMap struct {
+	mappings []*mecabPosMapping
+}
+
+func NewMeCabPosMap() *MeCabPosMap {
+	return &MeCabPosMap{}
+}
+
+func (m *MeCabPosMap) Add(from []string, to []string) error {
+	if len(from) == 0 || len(from) > PosDepth {
+		return fmt.Errorf("MeCab part of speech must have 1 to %d fields", PosDepth)
+	}
+	if len(to) != PosDepth {
+		return fmt.Errorf("Sudachi part of speech must have %d fields", PosDepth)
+	}
+	m.mappings = append(m.mappings, &mecabPosMapping{
+		from: from,
+		to:   to,
+	})
+	return nil
+}
+
+// ReadPosMap reads a table of the form
+//
+//	名詞,固有名詞,人名	名詞,固有名詞,人名,一般,*,*
+//
+// one mapping per line. Lines beginning with '#' are ignored.
+func (m *MeCabPosMap) ReadPosMap(input io.Reader) error {
+	r := lnreader.NewLineNumberReader(input)
+	for {
+		line, err := r.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if lnreader.IsSkipLine(line) {
+			continue
+		}
+		cols := strings.Fields(string(line))
+		if len(cols) != 2 {
+			return fmt.Errorf("invalid format at line %d", r.NumLine)
+		}
+		err = m.Add(strings.Split(cols[0], ","), strings.Split(cols[1], ","))
+		if err != nil {
+			return fmt.Errorf("%s at line %d", err, r.NumLine)
+		}
+	}
+	return nil
+}
+
+func (m *MeCabPosMap) Map(pos []string) []string {
+	var (
+		ret    []string
+		maxlen int
+	)
+L:
+	for _, mapping := range m.mappings {
+		if len(mapping.from) <= maxlen {
+			continue
+		}
+		for i, f := range mapping.from {
+			if i >= len(pos) || pos[i] != f {
+				continue L
+			}
+		}
+		ret = mapping.to
+		maxlen = len(mapping.from)
+	}
+	return ret
+}
+
+type mecabEntry struct {
+	surface        string
+	parameters     [3]int16
+	mecabPos       []string
+	pos            []string
+	dictionaryForm string
+	normalizedForm string
+	readingForm    string
+}
+
+// ReadMeCabIdDef reads left-id.def or right-id.def of a MeCab dictionary
+// and returns the number of the connection IDs defined in it.
+func ReadMeCabIdDef(input io.Reader) (int, error) {
+	r := lnreader.NewLineNumberReader(input)
+	size := 0
+	for {
+		line, err := r.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if lnreader.IsSkipLine(line) {
+			continue
+		}
+		cols := strings.Fields(string(line))
+		if len(cols) != 2 {
+			return 0, fmt.Errorf("invalid format at line %d", r.NumLine)
+		}
+		id, err := strconv.Atoi(cols[0])
+		if err != nil || id < 0 {
+			return 0, fmt.Errorf("invalid ID at line %d", r.NumLine)
+		}
+		if id >= size {
+			size = id + 1
+		}
+	}
+	return size, nil
+}
+
+// MeCabConverter converts MeCab dictionary source files into the source
+// format of DictionaryBuilder.
+type MeCabConverter struct {
+	columns           *MeCabColumns
+	posMap            *MeCabPosMap
+	NormalizeHeadword bool
+	// LeftIdSize and RightIdSize are the numbers of the IDs defined in
+	// left-id.def and right-id.def. Read rejects the rows whose IDs are
+	// out of range. Zero disables the check.
+	LeftIdSize  int
+	RightIdSize int
+	entries     []*mecabEntry
+}
+
+func NewMeCabConverter(columns *MeCabColumns, posMap *MeCabPosMap) *MeCabConverter {
+	return &MeCabConverter{
+		columns:           columns,
+		posMap:            posMap,
+		NormalizeHeadword: true,
+	}
+}
+
+func (c *MeCabConverter) column(record []string, i int) string {
+	if i < 0 || i >= len(record) || record[i] == "*" {
+		return ""
+	}
+	return record[i]
+}
+
+func (c *MeCabConverter) Read(input io.Reader) error {
+	r := csv.NewReader(bufio.NewReader(input))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.ReuseRecord = true
+
+	for numLine := 1; ; numLine++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < c.columns.Pos+1 {
+			return fmt.Errorf("too few columns at line %d", numLine)
+		}
+		if record[0] == "" {
+			return fmt.Errorf("empty surface at line %d", numLine)
+		}
+
+		entry := &mecabEntry{
+			surface: record[0],
+		}
+		for i := 0; i < 3; i++ {
+			p, err := strconv.ParseInt(record[i+1], 10, 16)
+			if err != nil {
+				return fmt.Errorf("%s: column %d at line %d", err, i+1, numLine)
+			}
+			if i == 0 && c.LeftIdSize > 0 && (p < 0 || p >= int64(c.LeftIdSize)) {
+				return fmt.Errorf("left-id %d is out of range at line %d", p, numLine)
+			}
+			if i == 1 && c.RightIdSize > 0 && (p < 0 || p >= int64(c.RightIdSize)) {
+				return fmt.Errorf("right-id %d is out of range at line %d", p, numLine)
+			}
+			entry.parameters[i] = int16(p)
+		}
+
+		mecabPos := make([]string, PosDepth, PosDepth)
+		for i := 0; i < PosDepth; i++ {
+			if c.columns.Pos+i < len(record) {
+				mecabPos[i] = record[c.columns.Pos+i]
+			} else {
+				mecabPos[i] = "*"
+			}
+		}
+		entry.mecabPos = mecabPos
+		entry.pos = mecabPos
+		if c.posMap != nil {
+			pos := c.posMap.Map(mecabPos)
+			if pos != nil {
+				entry.pos = pos
+			}
+		}
+
+		entry.dictionaryForm = c.column(record, c.columns.DictionaryForm)
+		entry.normalizedForm = c.column(record, c.columns.NormalizedForm)
+		if *c.columns == *UnidicColumns {
+			if i := strings.IndexByte(entry.normalizedForm, '-'); i > 0 {
+				// UniDic lemma has a sub-lemma such as "ジャンプ-jump"
+				entry.normalizedForm = entry.normalizedForm[:i]
+			}
+		}
+		if entry.normalizedForm == "" {
+			entry.normalizedForm = entry.surface
+		}
+		entry.readingForm = c.column(record, c.columns.ReadingForm)
+		if entry.readingForm == "" {
+			entry.readingForm = entry.surface
+		}
+
+		c.entries = append(c.entries, entry)
+	}
+	return nil
+}
+
+func (c *MeCabConverter) EntrySize() int {
+	return len(c.entries)
+}
+
+func mecabDictionaryFormKey(surface string, pos []string) string {
+	// the conjugation form is not compared
+	return surface + "," + strings.Join(pos[:PosDepth-1], ",")
+}
+
+// WriteLexicon writes the entries read so far in the source format of
+// DictionaryBuilder. wordIdOffset is the word ID of the first entry.
+func (c *MeCabConverter) WriteLexicon(writer io.Writer, wordIdOffset int) error {
+	baseForms := make(map[string]int, len(c.entries))
+	for i, entry := range c.entries {
+		key := mecabDictionaryFormKey(entry.surface, entry.mecabPos)
+		if _, ok := baseForms[key]; !ok {
+			baseForms[key] = i
+		}
+	}
+
+	bwriter := bufio.NewWriter(writer)
+	cols := make([]string, NumberOfColumns, NumberOfColumns)
+	for i, entry := range c.entries {
+		headword := entry.surface
+		if c.NormalizeHeadword {
+			headword = norm.NFKC.String(strings.ToLower(headword))
+		}
+		dicFormWordId := "*"
+		if entry.dictionaryForm != "" && entry.dictionaryForm != entry.surface {
+			wid, ok := baseForms[mecabDictionaryFormKey(entry.dictionaryForm, entry.mecabPos)]
+			if ok && wid != i {
+				dicFormWordId = strconv.Itoa(wid + wordIdOffset)
+			}
+		}
+
+		cols[0] = escapeLexiconField(headword)
+		cols[1] = strconv.Itoa(int(entry.parameters[0]))
+		cols[2] = strconv.Itoa(int(entry.parameters[1]))
+		cols[3] = strconv.Itoa(int(entry.parameters[2]))
+		cols[4] = escapeLexiconField(entry.surface)
+		for j, p := range entry.pos {
+			cols[5+j] = escapeLexiconField(p)
+		}
+		cols[11] = escapeLexiconField(entry.readingForm)
+		cols[12] = escapeLexiconField(entry.normalizedForm)
+		cols[13] = dicFormWordId
+		cols[14] = "A"
+		cols[15] = "*"
+		cols[16] = "*"
+		cols[17] = "*"
+
+		_, err := bwriter.WriteString(strings.Join(cols, ","))
+		if err != nil {
+			return err
+		}
+		err = bwriter.WriteByte('\n')
+		if err != nil {
+			return err
+		}
+	}
+	return bwriter.Flush()
+}
+
+var lexiconFieldEscaper = strings.NewReplacer(
+	`\`, `\u005c`,
+	",", `\u002c`,
+)
+
+func escapeLexiconField(s string) string {
+	return lexiconFieldEscaper.Replace(s)
+}
+
+// BuildLexiconFromMeCab is the same as BuildLexicon except that the entries
+// come from a MeCabConverter.
+func (dicbuilder *DictionaryBuilder) BuildLexiconFromMeCab(store PosIdStore, conv *MeCabConverter) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(conv.WriteLexicon(pw, dicbuilder.EntrySize()))
+	}()
+	err := dicbuilder.BuildLexicon(store, pr)
+	pr.CloseWithError(err)
+	return err
+}
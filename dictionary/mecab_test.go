@@ -0,0 +1,139 @@
+package dictionary
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var ipadicRows = `行く,1,2,100,動詞,自立,*,*,五段・カ行促音便,基本形,行く,イク,イク
+行っ,3,4,200,動詞,自立,*,*,五段・カ行促音便,連用タ接続,行く,イッ,イッ
+",",5,5,300,記号,読点,*,*,*,*,",",、,、
+ＡＢＣ,6,6,400,名詞,固有名詞,組織,*,*,*,*
+`
+
+func TestMeCabConverter(t *testing.T) {
+	posMap := NewMeCabPosMap()
+	err := posMap.ReadPosMap(strings.NewReader(`# comment
+動詞,自立	動詞,一般,*,*,五段-カ行,*
+記号	補助記号,読点,*,*,*,*
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	conv := NewMeCabConverter(IpadicColumns, posMap)
+	err = conv.Read(strings.NewReader(ipadicRows))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if conv.EntrySize() != 4 {
+		t.Fatalf("invalid result. want = 4, got = %d", conv.EntrySize())
+	}
+
+	var buf bytes.Buffer
+	err = conv.WriteLexicon(&buf, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		`行く,1,2,100,行く,動詞,一般,*,*,五段-カ行,*,イク,行く,*,A,*,*,*`,
+		`行っ,3,4,200,行っ,動詞,一般,*,*,五段-カ行,*,イッ,行く,0,A,*,*,*`,
+		`\u002c,5,5,300,\u002c,補助記号,読点,*,*,*,*,、,\u002c,*,A,*,*,*`,
+		`abc,6,6,400,ＡＢＣ,名詞,固有名詞,組織,*,*,*,ＡＢＣ,ＡＢＣ,*,A,*,*,*`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("invalid result. want = %d lines, got = %d", len(want), len(lines))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: want = %s, got = %s", i, want[i], lines[i])
+		}
+	}
+
+	dicbuilder := NewDictionaryBuilder(0, nil, false)
+	store := NewPosTable()
+	err = dicbuilder.BuildLexiconFromMeCab(store, conv)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if dicbuilder.EntrySize() != 4 {
+		t.Errorf("invalid result. want = 4, got = %d", dicbuilder.EntrySize())
+	}
+	if got := dicbuilder.wordEntries[2].WordInfo.Surface; got != "," {
+		t.Errorf("invalid result. want = \",\", got = %s", got)
+	}
+}
+
+func TestMeCabConverterIdRange(t *testing.T) {
+	size, err := ReadMeCabIdDef(strings.NewReader(`0 BOS/EOS,*,*,*,*,*,*
+1 名詞,一般,*,*,*,*,*
+2 名詞,固有名詞,*,*,*,*,*
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if size != 3 {
+		t.Fatalf("invalid result. want = 3, got = %d", size)
+	}
+
+	conv := NewMeCabConverter(IpadicColumns, nil)
+	conv.LeftIdSize = size
+	conv.RightIdSize = size
+	err = conv.Read(strings.NewReader(`東京,1,2,100,名詞,固有名詞,地域,*,*,*,東京,トウキョウ,トーキョー
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = conv.Read(strings.NewReader(`京都,3,1,100,名詞,固有名詞,地域,*,*,*,京都,キョウト,キョート
+`))
+	if err == nil || !strings.Contains(err.Error(), "left-id 3") {
+		t.Errorf("left-id out of range is not detected: %v", err)
+	}
+	err = conv.Read(strings.NewReader(`京都,1,-1,100,名詞,固有名詞,地域,*,*,*,京都,キョウト,キョート
+`))
+	if err == nil || !strings.Contains(err.Error(), "right-id -1") {
+		t.Errorf("right-id out of range is not detected: %v", err)
+	}
+}
+
+func TestMeCabConverterNormalizedForm(t *testing.T) {
+	tests := []struct {
+		columns *MeCabColumns
+		row     string
+		want    string
+	}{
+		{IpadicColumns, "Wi-Fi,1,1,100,名詞,固有名詞,一般,*,*,*,Wi-Fi,ワイファイ,ワイファイ\n", "Wi-Fi"},
+		{UnidicColumns, "ジャンプ,1,1,100,名詞,普通名詞,サ変可能,*,*,*,ジャンプ,ジャンプ-jump,ジャンプ,ジャンプ,ジャンプ\n", "ジャンプ"},
+	}
+	for _, tt := range tests {
+		conv := NewMeCabConverter(tt.columns, nil)
+		err := conv.Read(strings.NewReader(tt.row))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var buf bytes.Buffer
+		err = conv.WriteLexicon(&buf, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := strings.Split(buf.String(), ",")[12]; got != tt.want {
+			t.Errorf("%s: want = %s, got = %s", tt.row, tt.want, got)
+		}
+	}
+}
+
+func TestMeCabConverterOverflow(t *testing.T) {
+	for _, row := range []string{
+		"東京,40000,1,100,名詞,固有名詞,地域,*,*,*,東京,トウキョウ,トーキョー\n",
+		"東京,1,-40000,100,名詞,固有名詞,地域,*,*,*,東京,トウキョウ,トーキョー\n",
+		"東京,1,1,32768,名詞,固有名詞,地域,*,*,*,東京,トウキョウ,トーキョー\n",
+	} {
+		conv := NewMeCabConverter(IpadicColumns, nil)
+		if err := conv.Read(strings.NewReader(row)); err == nil {
+			t.Errorf("error is expected for %s", row)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/msnoigrs/gosudachi/internal/mmap"
+)
+
+const (
+	StorageMmap  = "mmap"
+	StorageHeap  = "heap"
+	StoragePread = "pread"
+)
+
+// StorageOptions tells how a dictionary file is loaded.
+type StorageOptions struct {
+	// Storage is "mmap" (default), "heap" or "pread".
+	// "heap" reads the whole file into memory. "pread" keeps everything
+	// but the wordInfos in memory and reads a wordInfo from the file
+	// each time it is needed.
+	Storage string
+	// Advice is the access pattern given to madvise for "mmap":
+	// "normal" (default), "random", "sequential" or "willneed".
+	Advice string
+	// Populate touches all the pages on load for "mmap".
+	Populate bool
+}
+
+func (o *StorageOptions) advice() (mmap.Advice, error) {
+	switch o.Advice {
+	case "", "normal":
+		return mmap.AdviceNormal, nil
+	case "random":
+		return mmap.AdviceRandom, nil
+	case "sequential":
+		return mmap.AdviceSequential, nil
+	case "willneed":
+		return mmap.AdviceWillNeed, nil
+	}
+	return mmap.AdviceNormal, fmt.Errorf("%s is unknown storage advice", o.Advice)
+}
+
+// Storage holds the content of a dictionary file.
+type Storage interface {
+	// Bytes returns the content from the beginning of the file. It may
+	// end before the wordInfos, in which case ReaderAt is not nil.
+	Bytes() []byte
+	// ReaderAt returns the reader of the whole file if Bytes does not
+	// contain the wordInfos, nil otherwise.
+	ReaderAt() io.ReaderAt
+	// Size returns the size of the file.
+	Size() int64
+	Close() error
+}
+
+// OpenStorage opens filename. options may be nil.
+func OpenStorage(filename string, utf16string bool, options *StorageOptions) (Storage, error) {
+	if options == nil {
+		options = &StorageOptions{}
+	}
+	switch options.Storage {
+	case "", StorageMmap:
+		return openMmapStorage(filename, options)
+	case StorageHeap:
+		return openHeapStorage(filename)
+	case StoragePread:
+		return openPreadStorage(filename, utf16string)
+	}
+	return nil, fmt.Errorf("%s is unknown storage", options.Storage)
+}
+
+type mmapStorage struct {
+	fd   *os.File
+	fmap []byte
+}
+
+func openMmapStorage(filename string, options *StorageOptions) (*mmapStorage, error) {
+	advice, err := options.advice()
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	finfo, err := fd.Stat()
+	if err != nil {
+		_ = fd.Close()
+		return nil, err
+	}
+	fmap, err := mmap.Mmap(fd, false, 0, finfo.Size())
+	if err != nil {
+		_ = fd.Close()
+		return nil, err
+	}
+
+	if advice != mmap.AdviceNormal {
+		err = mmap.Madvise(fmap, advice)
+		if err != nil {
+			_ = mmap.Munmap(fmap)
+			_ = fd.Close()
+			return nil, err
+		}
+	}
+	if options.Populate {
+		mmap.Populate(fmap)
+	}
+
+	return &mmapStorage{
+		fd:   fd,
+		fmap: fmap,
+	}, nil
+}
+
+func (s *mmapStorage) Bytes() []byte {
+	return s.fmap
+}
+
+func (s *mmapStorage) ReaderAt() io.ReaderAt {
+	return nil
+}
+
+func (s *mmapStorage) Size() int64 {
+	return int64(len(s.fmap))
+}
+
+func (s *mmapStorage) Close() error {
+	err := mmap.Munmap(s.fmap)
+	if err != nil {
+		return err
+	}
+	return s.fd.Close()
+}
+
+type heapStorage struct {
+	b []byte
+}
+
+func openHeapStorage(filename string) (*heapStorage, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &heapStorage{
+		b: b,
+	}, nil
+}
+
+func (s *heapStorage) Bytes() []byte {
+	return s.b
+}
+
+func (s *heapStorage) ReaderAt() io.ReaderAt {
+	return nil
+}
+
+func (s *heapStorage) Size() int64 {
+	return int64(len(s.b))
+}
+
+func (s *heapStorage) Close() error {
+	s.b = nil
+	return nil
+}
+
+type preadStorage struct {
+	fd       *os.File
+	resident []byte
+	size     int64
+}
+
+func openPreadStorage(filename string, utf16string bool) (*preadStorage, error) {
+	fd, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	finfo, err := fd.Stat()
+	if err != nil {
+		_ = fd.Close()
+		return nil, err
+	}
+
+	scanner := &layoutScanner{
+		r:    fd,
+		size: finfo.Size(),
+	}
+	if utf16string {
+		scanner.charSize = 2
+	} else {
+		scanner.charSize = 1
+	}
+	err = scanner.scan()
+	if err != nil {
+		_ = fd.Close()
+		return nil, fmt.Errorf("%s: %s", err, filename)
+	}
+
+	return &preadStorage{
+		fd:       fd,
+		resident: scanner.buf,
+		size:     finfo.Size(),
+	}, nil
+}
+
+func (s *preadStorage) Bytes() []byte {
+	return s.resident
+}
+
+func (s *preadStorage) ReaderAt() io.ReaderAt {
+	return s.fd
+}
+
+func (s *preadStorage) Size() int64 {
+	return s.size
+}
+
+func (s *preadStorage) Close() error {
+	s.resident = nil
+	return s.fd.Close()
+}
+
+const layoutScanReadAhead = 64 * 1024
+
+var errTruncatedDictionary = errors.New("truncated dictionary")
+
+// layoutScanner reads a dictionary file from the beginning up to the end
+// of the wordInfo offsets, following the sizes of the sections.
+type layoutScanner struct {
+	r        io.ReaderAt
+	size     int64
+	charSize int
+	buf      []byte
+	offset   int
+}
+
+// ensure reads the file so that buf has n more bytes from offset.
+func (sc *layoutScanner) ensure(n int) error {
+	end := sc.offset + n
+	if int64(end) > sc.size {
+		return errTruncatedDictionary
+	}
+	if end <= len(sc.buf) {
+		return nil
+	}
+	readEnd := end + layoutScanReadAhead
+	if int64(readEnd) > sc.size {
+		readEnd = int(sc.size)
+	}
+	buf := make([]byte, readEnd)
+	copy(buf, sc.buf)
+	_, err := sc.r.ReadAt(buf[len(sc.buf):], int64(len(sc.buf)))
+	if err != nil && err != io.EOF {
+		return err
+	}
+	sc.buf = buf
+	return nil
+}
+
+func (sc *layoutScanner) skip(n int) error {
+	err := sc.ensure(n)
+	if err != nil {
+		return err
+	}
+	sc.offset += n
+	return nil
+}
+
+func (sc *layoutScanner) uint16() (int, error) {
+	err := sc.ensure(2)
+	if err != nil {
+		return 0, err
+	}
+	var v uint16
+	sc.offset, v = bufferToUint16(sc.buf, sc.offset)
+	return int(v), nil
+}
+
+func (sc *layoutScanner) uint32() (int, error) {
+	err := sc.ensure(4)
+	if err != nil {
+		return 0, err
+	}
+	var v uint32
+	sc.offset, v = bufferToUint32(sc.buf, sc.offset)
+	return int(v), nil
+}
+
+func (sc *layoutScanner) skipString() error {
+	err := sc.ensure(1)
+	if err != nil {
+		return err
+	}
+	if sc.buf[sc.offset]&0x80 != 0 {
+		err = sc.ensure(2)
+		if err != nil {
+			return err
+		}
+	}
+	var length int
+	sc.offset, length = bufferToStringLength(sc.buf, sc.offset)
+	return sc.skip(length * sc.charSize)
+}
+
+func (sc *layoutScanner) scan() error {
+	err := sc.ensure(HeaderStorageSize)
+	if err != nil {
+		return err
+	}
+	header := ParseDictionaryHeader(sc.buf, 0)
+	sc.offset = HeaderStorageSize
+
+	if header.Version == SystemDictVersion || header.Version == UserDictVersion2 {
+		posLen, err := sc.uint16()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < posLen*PosDepth; i++ {
+			err = sc.skipString()
+			if err != nil {
+				return err
+			}
+		}
+		leftIdSize, err := sc.uint16()
+		if err != nil {
+			return err
+		}
+		rightIdSize, err := sc.uint16()
+		if err != nil {
+			return err
+		}
+		err = sc.skip(2 * leftIdSize * rightIdSize)
+		if err != nil {
+			return err
+		}
+	} else if header.Version != UserDictVersion {
+		return errors.New("invalid dictionary")
+	}
+
+	trieSize, err := sc.uint32()
+	if err != nil {
+		return err
+	}
+	err = sc.skip(4 * trieSize)
+	if err != nil {
+		return err
+	}
+	wordIdTableSize, err := sc.uint32()
+	if err != nil {
+		return err
+	}
+	err = sc.skip(wordIdTableSize)
+	if err != nil {
+		return err
+	}
+	wordSize, err := sc.uint32()
+	if err != nil {
+		return err
+	}
+	err = sc.skip(wordParameterListElementSize * wordSize)
+	if err != nil {
+		return err
+	}
+	err = sc.skip(4 * wordSize)
+	if err != nil {
+		return err
+	}
+	sc.buf = sc.buf[:sc.offset]
+	return nil
+}
@@ -0,0 +1,149 @@
+package dictionary
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	b := buildSystemDictionary(t, generateLexicon(1000), 1)
+	dh := NewDictionaryHeader(SystemDictVersion, 0, "")
+	dh.Metadata = NewDictionaryMetadata()
+	dh.Metadata.Put(MetadataLicense, "Apache-2.0")
+	mb, err := dh.MetadataToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b = append(b, mb...)
+
+	f, err := ioutil.TempFile("", "storage")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	f.Close()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want, err := NewBinaryDictionary(f.Name(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer want.Close()
+
+	for _, options := range []*StorageOptions{
+		{Storage: StorageMmap, Advice: "random", Populate: true},
+		{Storage: StorageHeap},
+		{Storage: StoragePread},
+	} {
+		got, err := NewBinaryDictionaryWithStorage(f.Name(), false, options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", options.Storage, err)
+		}
+		if v, _ := got.Header.Metadata.Get(MetadataLicense); v != "Apache-2.0" {
+			t.Errorf("%s: invalid metadata: %s", options.Storage, v)
+		}
+		if got.Lexicon.Size() != want.Lexicon.Size() {
+			t.Fatalf("%s: invalid size: %d", options.Storage, got.Lexicon.Size())
+		}
+		for wordId := int32(0); wordId < want.Lexicon.Size(); wordId++ {
+			if got.Lexicon.GetCost(wordId) != want.Lexicon.GetCost(wordId) {
+				t.Errorf("%s: invalid cost of %d", options.Storage, wordId)
+			}
+			gotwi, err := got.Lexicon.GetWordInfo(wordId)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", options.Storage, err)
+			}
+			wantwi, _ := want.Lexicon.GetWordInfo(wordId)
+			if !reflect.DeepEqual(gotwi, wantwi) {
+				t.Errorf("%s: invalid wordInfo of %d", options.Storage, wordId)
+			}
+		}
+		err = got.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", options.Storage, err)
+		}
+	}
+
+	pread, err := NewBinaryDictionaryWithStorage(f.Name(), false, &StorageOptions{Storage: StoragePread})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer pread.Close()
+	wordInfos := pread.Lexicon.wordInfos
+	if wordInfos.end != int64(len(b)-len(mb)) {
+		t.Errorf("the lexicon must end before the metadata. want = %d, got = %d", len(b)-len(mb), wordInfos.end)
+	}
+	reader := &recordingReaderAt{r: wordInfos.reader}
+	wordInfos.setReader(reader, wordInfos.end)
+	last := want.Lexicon.Size() - 1
+	gotwi, err := pread.Lexicon.GetWordInfo(last)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantwi, _ := want.Lexicon.GetWordInfo(last)
+	if !reflect.DeepEqual(gotwi, wantwi) {
+		t.Errorf("invalid wordInfo of %d", last)
+	}
+	if reader.maxEnd > wordInfos.end {
+		t.Errorf("the metadata is read. end = %d, read = %d", wordInfos.end, reader.maxEnd)
+	}
+
+	reader.reads = 0
+	posIds, err := pread.Lexicon.GetPosIds()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for wordId, posId := range posIds {
+		if want, _ := want.Lexicon.GetPosId(int32(wordId)); posId != want {
+			t.Errorf("invalid posId of %d: want = %d, got = %d", wordId, want, posId)
+		}
+	}
+	if reader.reads != 1 {
+		t.Errorf("the wordInfos must be read at once: %d", reader.reads)
+	}
+	if reader.maxEnd > wordInfos.end {
+		t.Errorf("the metadata is read. end = %d, read = %d", wordInfos.end, reader.maxEnd)
+	}
+
+	reader.err = errors.New("I/O error")
+	if _, err := pread.Lexicon.GetWordInfo(0); err == nil {
+		t.Error("error is expected for a failed read")
+	}
+	if _, err := pread.Lexicon.GetPosId(1); err == nil {
+		t.Error("error is expected for a failed read")
+	}
+	reader.err = nil
+	if _, err := pread.Lexicon.GetWordInfo(0); err != nil {
+		t.Errorf("a failed read must not affect the others: %s", err)
+	}
+
+	_, err = NewBinaryDictionaryWithStorage(f.Name(), false, &StorageOptions{Storage: "unknown"})
+	if err == nil {
+		t.Error("error is expected for unknown storage")
+	}
+}
+
+type recordingReaderAt struct {
+	r      io.ReaderAt
+	maxEnd int64
+	reads  int
+	err    error
+}
+
+func (r *recordingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.reads++
+	if end := off + int64(len(p)); end > r.maxEnd {
+		r.maxEnd = end
+	}
+	return r.r.ReadAt(p, off)
+}
@@ -0,0 +1,96 @@
+package gosudachi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+// testLexicon has the words with the connection IDs 1 to 4. The IDs of
+// the connection matrix of the test dictionary are 0 to 4.
+const testLexicon = `東京,1,1,2816,東京,名詞,固有名詞,地名,一般,*,*,トウキョウ,東京,*,A,*,*,*
+都,2,2,2914,都,名詞,普通名詞,一般,*,*,*,ト,都,*,A,*,*,*
+に,3,3,1000,に,助詞,格助詞,*,*,*,*,ニ,に,*,A,*,*,*
+附,2,2,3000,附,名詞,普通名詞,一般,*,*,*,フ,付,*,A,*,*,*
+a都,2,2,100,a都,名詞,普通名詞,一般,*,*,*,アト,a都,*,A,*,*,*
+行く,4,4,5105,行く,動詞,非自立可能,*,*,五段-カ行,終止形-一般,イク,行く,*,A,*,*,*
+`
+
+const testMatrixSize = 5
+
+func testMatrix() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %d\n", testMatrixSize, testMatrixSize)
+	for l := 0; l < testMatrixSize; l++ {
+		for r := 0; r < testMatrixSize; r++ {
+			fmt.Fprintf(&b, "%d %d %d\n", l, r, 10*l+r)
+		}
+	}
+	return b.String()
+}
+
+// newTestDictionary returns the dictionary of testLexicon with a
+// SimpleOovProviderPlugin and a RegexOovProviderPlugin giving the runs of
+// alphabets, which cannot begin in the middle of a run.
+func newTestDictionary(t *testing.T, pathRewritePlugins []PathRewritePlugin, editConnectionCostPlugins []EditConnectionCostPlugin) *JapaneseDictionary {
+	f, err := ioutil.TempFile("", "gosudachi")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	hb, err := dictionary.NewDictionaryHeader(dictionary.SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dicbuilder := dictionary.NewDictionaryBuilder(int64(len(hb)), nil, false)
+	store := dictionary.NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(testLexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader(testMatrix()), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	alphaPattern := "[A-Za-z]+"
+	var id, cost, alphaCost int16 = 2, 10000, 5000
+	dict, err := NewJapaneseDictionary(
+		&BaseConfig{SystemDict: f.Name(), Storage: "heap"},
+		[]InputTextPlugin{},
+		[]OovProviderPlugin{
+			NewSimpleOovProviderPlugin(&SimpleOovProviderPluginConfig{
+				OovPos:  &oovPos,
+				LeftId:  &id,
+				RightId: &id,
+				Cost:    &cost,
+			}),
+			NewRegexOovProviderPlugin(&RegexOovProviderPluginConfig{
+				Patterns: &[]RegexOovPatternConfig{
+					{Pattern: &alphaPattern, OovPos: &oovPos, LeftId: &id, RightId: &id, Cost: &alphaCost},
+				},
+			}),
+		},
+		pathRewritePlugins,
+		editConnectionCostPlugins,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return dict
+}
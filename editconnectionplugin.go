@@ -0,0 +1,112 @@
+package gosudachi
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+type EditConnectionRuleConfig struct {
+	Left       *ConnectionPatternConfig
+	Right      *ConnectionPatternConfig
+	CostOffset *int
+	Inhibit    *bool
+}
+
+type EditConnectionPluginConfig struct {
+	Rules *[]EditConnectionRuleConfig
+}
+
+type editConnectionRule struct {
+	left       []int16
+	right      []int16
+	costOffset int
+	inhibit    bool
+}
+
+// EditConnectionPlugin adds an offset to the costs of the connections
+// between the words matching the patterns, or inhibits them. The costs of
+// the inhibited connections are left as they are.
+type EditConnectionPlugin struct {
+	config  *EditConnectionPluginConfig
+	lexicon *dictionary.LexiconSet
+	rules   []*editConnectionRule
+}
+
+func NewEditConnectionPlugin(config *EditConnectionPluginConfig) *EditConnectionPlugin {
+	if config == nil {
+		config = &EditConnectionPluginConfig{}
+	}
+	return &EditConnectionPlugin{
+		config: config,
+	}
+}
+
+func (p *EditConnectionPlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &EditConnectionPluginConfig{}
+	}
+	return p.config
+}
+
+func (p *EditConnectionPlugin) SetLexicon(lexicon *dictionary.LexiconSet) {
+	p.lexicon = lexicon
+}
+
+func (p *EditConnectionPlugin) SetUp(grammar *dictionary.Grammar) error {
+	if p.config.Rules == nil {
+		return fmt.Errorf("EditConnectionPlugin: rules is not specified")
+	}
+	resolver := newConnectionIdResolver(grammar, p.lexicon)
+	p.rules = []*editConnectionRule{}
+	for i, rc := range *p.config.Rules {
+		rule := &editConnectionRule{
+			inhibit: rc.Inhibit != nil && *rc.Inhibit,
+		}
+		if rc.CostOffset != nil {
+			rule.costOffset = *rc.CostOffset
+		}
+		if !rule.inhibit && rule.costOffset == 0 {
+			return fmt.Errorf("EditConnectionPlugin: neither costOffset nor inhibit is specified in rules[%d]", i)
+		}
+		left, right, err := resolver.resolvePair(&ConnectionPairConfig{
+			Left:  rc.Left,
+			Right: rc.Right,
+		})
+		if err != nil {
+			return fmt.Errorf("EditConnectionPlugin: %s in rules[%d]", err, i)
+		}
+		rule.left = left
+		rule.right = right
+		p.rules = append(p.rules, rule)
+	}
+	p.config = nil
+	p.lexicon = nil
+	return nil
+}
+
+func (p *EditConnectionPlugin) Edit(grammar *dictionary.Grammar) error {
+	for _, rule := range p.rules {
+		for _, leftId := range rule.left {
+			for _, rightId := range rule.right {
+				if rule.inhibit {
+					InhibitConnection(grammar, leftId, rightId)
+					continue
+				}
+				cost := int(grammar.GetConnectCost(leftId, rightId))
+				if cost == int(dictionary.InhibitedConnection) {
+					continue
+				}
+				cost += rule.costOffset
+				if cost >= int(dictionary.InhibitedConnection) {
+					cost = int(dictionary.InhibitedConnection) - 1
+				} else if cost < math.MinInt16 {
+					cost = math.MinInt16
+				}
+				grammar.SetConnectCost(leftId, rightId, int16(cost))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package gosudachi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+type connectionCost struct {
+	left  int16
+	right int16
+	cost  int16
+}
+
+func checkConnectionCosts(t *testing.T, name string, grammar *dictionary.Grammar, want []connectionCost) {
+	for _, c := range want {
+		if got := grammar.GetConnectCost(c.left, c.right); got != c.cost {
+			t.Errorf("%s: invalid cost of (%d, %d): want = %d, got = %d", name, c.left, c.right, c.cost, got)
+		}
+	}
+}
+
+func TestEditConnectionPlugin(t *testing.T) {
+	base := newTestDictionary(t, []PathRewritePlugin{}, []EditConnectionCostPlugin{})
+	defer base.Close()
+	cost := func(left, right int16) int16 {
+		return base.grammar.GetConnectCost(left, right)
+	}
+
+	zero := 0
+	offset := 100
+	negative := -5
+	inhibit := true
+	noun := []string{"名詞"}
+	commonNoun := []string{"名詞", "普通名詞"}
+	particle := []string{"助詞"}
+	tokyo := "東京"
+	iku := "行く"
+	plugin := NewEditConnectionPlugin(&EditConnectionPluginConfig{
+		Rules: &[]EditConnectionRuleConfig{
+			{
+				Left:       &ConnectionPatternConfig{POS: &noun},
+				Right:      &ConnectionPatternConfig{POS: &particle},
+				CostOffset: &offset,
+			},
+			{
+				Left:    &ConnectionPatternConfig{Surface: &iku},
+				Right:   &ConnectionPatternConfig{POS: &commonNoun},
+				Inhibit: &inhibit,
+			},
+			{
+				Left:       &ConnectionPatternConfig{Id: &zero},
+				Right:      &ConnectionPatternConfig{Surface: &tokyo, POS: &noun},
+				CostOffset: &negative,
+			},
+		},
+	})
+	dict := newTestDictionary(t, []PathRewritePlugin{}, []EditConnectionCostPlugin{plugin})
+	defer dict.Close()
+
+	checkConnectionCosts(t, "EditConnectionPlugin", dict.grammar, []connectionCost{
+		{1, 3, cost(1, 3) + 100},
+		{2, 3, cost(2, 3) + 100},
+		{4, 3, cost(4, 3)},
+		{4, 2, dictionary.InhibitedConnection},
+		{4, 1, cost(4, 1)},
+		{0, 1, cost(0, 1) - 5},
+		{0, 2, cost(0, 2)},
+	})
+
+	outOfRange, negative := testMatrixSize, -1
+	for _, rules := range [][]EditConnectionRuleConfig{
+		{{Left: &ConnectionPatternConfig{POS: &noun}, Right: &ConnectionPatternConfig{POS: &particle}}},
+		{{Left: &ConnectionPatternConfig{POS: &[]string{"形容詞"}}, Right: &ConnectionPatternConfig{POS: &particle}, CostOffset: &offset}},
+		{{Left: &ConnectionPatternConfig{Id: &outOfRange}, Right: &ConnectionPatternConfig{POS: &particle}, CostOffset: &offset}},
+		{{Left: &ConnectionPatternConfig{POS: &noun}, Right: &ConnectionPatternConfig{Id: &negative}, CostOffset: &offset}},
+	} {
+		plugin := NewEditConnectionPlugin(&EditConnectionPluginConfig{Rules: &rules})
+		plugin.SetLexicon(dict.lexicon)
+		if err := plugin.SetUp(dict.grammar); err == nil {
+			t.Errorf("error is expected for %v", rules)
+		}
+	}
+}
+
+func TestInhibitConnectionPlugin(t *testing.T) {
+	base := newTestDictionary(t, []PathRewritePlugin{}, []EditConnectionCostPlugin{})
+	defer base.Close()
+	cost := func(left, right int16) int16 {
+		return base.grammar.GetConnectCost(left, right)
+	}
+
+	particle := []string{"助詞"}
+	godan := []string{"動詞", "*", "*", "*", "五段-カ行"}
+	plugin := NewInhibitConnectionPlugin([]*[]int{{0, 4}})
+	config := plugin.GetConfigStruct().(*InhibitConnectionPluginConfig)
+	config.InhibitedPatternPair = &[]ConnectionPairConfig{
+		{
+			Left:  &ConnectionPatternConfig{POS: &particle},
+			Right: &ConnectionPatternConfig{POS: &godan},
+		},
+	}
+	dict := newTestDictionary(t, []PathRewritePlugin{}, []EditConnectionCostPlugin{plugin})
+	defer dict.Close()
+
+	checkConnectionCosts(t, "InhibitConnectionPlugin", dict.grammar, []connectionCost{
+		{0, 4, dictionary.InhibitedConnection},
+		{3, 4, dictionary.InhibitedConnection},
+		{3, 3, cost(3, 3)},
+		{2, 4, cost(2, 4)},
+	})
+
+	for _, pair := range [][]int{{testMatrixSize, 0}, {0, 65536}} {
+		plugin := NewInhibitConnectionPlugin([]*[]int{&pair})
+		err := plugin.SetUp(dict.grammar)
+		want := "InhibitConnectionPlugin: id"
+		if err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("%v: want = %s..., got = %v", pair, want, err)
+		}
+	}
+}
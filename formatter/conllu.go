@@ -0,0 +1,170 @@
+package formatter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+// UPOSMapping maps the parts of speech matching POS to a universal part
+// of speech. "*" in POS matches anything and the omitted trailing levels
+// are "*".
+type UPOSMapping struct {
+	POS  []string
+	UPOS string
+}
+
+// DefaultUPOSTable is the mapping used if no table is given. The first
+// matching entry is used, so the specific ones come first.
+var DefaultUPOSTable = []UPOSMapping{
+	{[]string{"名詞", "固有名詞"}, "PROPN"},
+	{[]string{"名詞", "数詞"}, "NUM"},
+	{[]string{"名詞"}, "NOUN"},
+	{[]string{"代名詞"}, "PRON"},
+	{[]string{"形状詞", "助動詞語幹"}, "AUX"},
+	{[]string{"形状詞"}, "ADJ"},
+	{[]string{"連体詞"}, "DET"},
+	{[]string{"副詞"}, "ADV"},
+	{[]string{"接続詞"}, "CCONJ"},
+	{[]string{"感動詞"}, "INTJ"},
+	{[]string{"動詞"}, "VERB"},
+	{[]string{"形容詞"}, "ADJ"},
+	{[]string{"助動詞"}, "AUX"},
+	{[]string{"助詞", "接続助詞"}, "SCONJ"},
+	{[]string{"助詞", "準体助詞"}, "SCONJ"},
+	{[]string{"助詞", "終助詞"}, "PART"},
+	{[]string{"助詞"}, "ADP"},
+	{[]string{"接頭辞"}, "NOUN"},
+	{[]string{"接尾辞", "形状詞的"}, "ADJ"},
+	{[]string{"接尾辞", "形容詞的"}, "ADJ"},
+	{[]string{"接尾辞", "動詞的"}, "VERB"},
+	{[]string{"接尾辞"}, "NOUN"},
+	{[]string{"補助記号", "空白"}, "SYM"},
+	{[]string{"補助記号"}, "PUNCT"},
+	{[]string{"記号"}, "SYM"},
+	{[]string{"空白"}, "SYM"},
+}
+
+// ReadUPOSTable reads a mapping table. Each line has a comma separated
+// part of speech pattern and a universal part of speech separated by a
+// tab. Empty lines and lines starting with "#" are ignored.
+func ReadUPOSTable(r io.Reader) ([]UPOSMapping, error) {
+	table := []UPOSMapping{}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) != 2 {
+			return nil, fmt.Errorf("invalid format at line %d", n)
+		}
+		pos := strings.Split(strings.TrimSpace(cols[0]), ",")
+		if len(pos) > dictionary.PosDepth {
+			return nil, fmt.Errorf("pos is too long at line %d", n)
+		}
+		table = append(table, UPOSMapping{
+			POS:  pos,
+			UPOS: strings.TrimSpace(cols[1]),
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// LookupUPOS returns the universal part of speech of pos, or "X" if no
+// entry of table matches.
+func LookupUPOS(table []UPOSMapping, pos []string) string {
+	for _, mapping := range table {
+		matched := true
+		for i, p := range mapping.POS {
+			if p != "*" && p != pos[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return mapping.UPOS
+		}
+	}
+	return "X"
+}
+
+// CoNLLUFormatter writes a sentence of CoNLL-U for each line. The
+// morphemes consisting of white spaces are not written as the words, but
+// they make SpaceAfter of the preceding words. MISC also has the offsets
+// of the characters in the line.
+type CoNLLUFormatter struct {
+	uposTable []UPOSMapping
+	sentId    int
+}
+
+// NewCoNLLUFormatter returns a CoNLLUFormatter. DefaultUPOSTable is used
+// if uposTable is nil.
+func NewCoNLLUFormatter(uposTable []UPOSMapping) *CoNLLUFormatter {
+	if uposTable == nil {
+		uposTable = DefaultUPOSTable
+	}
+	return &CoNLLUFormatter{
+		uposTable: uposTable,
+	}
+}
+
+func isSpaces(s string) bool {
+	return strings.TrimFunc(s, unicode.IsSpace) == ""
+}
+
+// conlluField replaces the characters not allowed in a field.
+func conlluField(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func (f *CoNLLUFormatter) Format(w io.Writer, text string, ms *gosudachi.MorphemeList) error {
+	words := make([]*gosudachi.Morpheme, 0, ms.Length())
+	for i := 0; i < ms.Length(); i++ {
+		m := ms.Get(i)
+		if !isSpaces(m.Surface()) {
+			words = append(words, m)
+		}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	f.sentId++
+	fmt.Fprintf(w, "# sent_id = %d\n", f.sentId)
+	fmt.Fprintf(w, "# text = %s\n", conlluField(text))
+	runes := []rune(text)
+	for i, m := range words {
+		pos := m.PartOfSpeech()
+		misc := fmt.Sprintf("CharOffsetBegin=%d|CharOffsetEnd=%d", m.Begin(), m.End())
+		if m.End() >= len(runes) || !unicode.IsSpace(runes[m.End()]) {
+			misc += "|SpaceAfter=No"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t_\t_\t_\t_\t%s\n",
+			i+1,
+			conlluField(m.Surface()),
+			conlluField(m.DictionaryForm()),
+			LookupUPOS(f.uposTable, pos),
+			conlluField(strings.Join(pos, ",")),
+			misc)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,58 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUPOSTable(t *testing.T) {
+	tests := []struct {
+		pos  string
+		want string
+	}{
+		{"名詞,固有名詞,地名,一般,*,*", "PROPN"},
+		{"名詞,普通名詞,一般,*,*,*", "NOUN"},
+		{"助詞,接続助詞,*,*,*,*", "SCONJ"},
+		{"補助記号,句点,*,*,*,*", "PUNCT"},
+		{"未定義,*,*,*,*,*", "X"},
+	}
+	for _, tt := range tests {
+		if got := LookupUPOS(DefaultUPOSTable, strings.Split(tt.pos, ",")); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.pos, got, tt.want)
+		}
+	}
+
+	table, err := ReadUPOSTable(strings.NewReader("# comment\n\n名詞,*,地名\tPROPN\n*\tNOUN\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := LookupUPOS(table, strings.Split("名詞,固有名詞,地名,一般,*,*", ",")); got != "PROPN" {
+		t.Errorf("got %s, want PROPN", got)
+	}
+	if got := LookupUPOS(table, strings.Split("動詞,一般,*,*,*,*", ",")); got != "NOUN" {
+		t.Errorf("got %s, want NOUN", got)
+	}
+
+	if _, err := ReadUPOSTable(strings.NewReader("名詞 NOUN\n")); err == nil {
+		t.Errorf("no error for an invalid line")
+	}
+}
+
+func TestCoNLLUFormatter(t *testing.T) {
+	// the line of white spaces has no sentence, and the white space
+	// between 東京都 and に is not a word
+	got := format(t, NewCoNLLUFormatter(nil), "C", "東京都 に行く", " ", "行く")
+	want := "# sent_id = 1\n" +
+		"# text = 東京都 に行く\n" +
+		"1\t東京都\t東京都\tPROPN\t名詞,固有名詞,地名,一般,*,*\t_\t_\t_\t_\tCharOffsetBegin=0|CharOffsetEnd=3\n" +
+		"2\tに\tに\tADP\t助詞,格助詞,*,*,*,*\t_\t_\t_\t_\tCharOffsetBegin=4|CharOffsetEnd=5|SpaceAfter=No\n" +
+		"3\t行く\t行く\tVERB\t動詞,非自立可能,*,*,五段-カ行,終止形-一般\t_\t_\t_\t_\tCharOffsetBegin=5|CharOffsetEnd=7|SpaceAfter=No\n" +
+		"\n" +
+		"# sent_id = 2\n" +
+		"# text = 行く\n" +
+		"1\t行く\t行く\tVERB\t動詞,非自立可能,*,*,五段-カ行,終止形-一般\t_\t_\t_\t_\tCharOffsetBegin=0|CharOffsetEnd=2|SpaceAfter=No\n" +
+		"\n"
+	if got != want {
+		t.Errorf("invalid output: want = %q, got = %q", want, got)
+	}
+}
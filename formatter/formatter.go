@@ -0,0 +1,107 @@
+// Package formatter writes the results of the tokenization in the
+// output formats of the commands.
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// Formatter writes the morphemes of a line of the input text.
+type Formatter interface {
+	Format(w io.Writer, text string, ms *gosudachi.MorphemeList) error
+}
+
+// Finisher is implemented by a Formatter that writes a trailer after
+// the last line.
+type Finisher interface {
+	Finish(w io.Writer) error
+}
+
+// Finish writes the trailer of f if it has one.
+func Finish(f Formatter, w io.Writer) error {
+	if fi, ok := f.(Finisher); ok {
+		return fi.Finish(w)
+	}
+	return nil
+}
+
+type Options struct {
+	// PrintAll makes the sudachi format print all the fields.
+	PrintAll bool
+	// Splits makes the json formats include the A and B unit splits.
+	Splits bool
+	// MeCabFeatures is the order of the feature columns of the mecab
+	// format. DefaultMeCabFeatures is used if it is empty.
+	MeCabFeatures []string
+	// UPOSTable maps the parts of speech to UPOS in the conllu format.
+	// DefaultUPOSTable is used if it is nil.
+	UPOSTable []UPOSMapping
+}
+
+// Names lists the names of the formats given to New.
+var Names = []string{"sudachi", "json", "jsonl", "mecab", "wakati", "conllu"}
+
+// New returns the Formatter of the format name.
+func New(name string, options *Options) (Formatter, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	switch name {
+	case "", "sudachi":
+		return NewSudachiFormatter(options.PrintAll), nil
+	case "json":
+		return NewJSONFormatter(true, options.Splits), nil
+	case "jsonl":
+		return NewJSONFormatter(false, options.Splits), nil
+	case "mecab":
+		f, err := NewMeCabFormatter(options.MeCabFeatures)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	case "wakati":
+		return NewWakatiFormatter(), nil
+	case "conllu":
+		return NewCoNLLUFormatter(options.UPOSTable), nil
+	}
+	return nil, fmt.Errorf("unknown format: %s (%s)", name, strings.Join(Names, ", "))
+}
+
+// SudachiFormatter writes the tab separated fields of each morpheme and
+// "EOS" at the end of a line, which is the format of Sudachi.
+type SudachiFormatter struct {
+	printAll bool
+}
+
+func NewSudachiFormatter(printAll bool) *SudachiFormatter {
+	return &SudachiFormatter{
+		printAll: printAll,
+	}
+}
+
+func (f *SudachiFormatter) Format(w io.Writer, text string, ms *gosudachi.MorphemeList) error {
+	for i := 0; i < ms.Length(); i++ {
+		m := ms.Get(i)
+
+		fmt.Fprintf(w, "%s\t%s\t%s",
+			m.Surface(),
+			strings.Join(m.PartOfSpeech(), ","),
+			m.NormalizedForm())
+		if f.printAll {
+			fmt.Fprintf(w, "\t%s\t%s\t%d",
+				m.DictionaryForm(),
+				m.ReadingForm(),
+				m.GetDictionaryId())
+			if m.IsOOV() {
+				fmt.Fprintf(w, "\t(OOV)")
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	_, err := fmt.Fprintln(w, "EOS")
+	return err
+}
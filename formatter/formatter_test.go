@@ -0,0 +1,122 @@
+package formatter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+// testLexicon has 東京都, which is split into 東京 and 都 in the A mode.
+const testLexicon = `東京,1,1,2816,東京,名詞,固有名詞,地名,一般,*,*,トウキョウ,東京,*,A,*,*,*
+都,2,2,2914,都,名詞,普通名詞,一般,*,*,*,ト,都,*,A,*,*,*
+東京都,1,2,2000,東京都,名詞,固有名詞,地名,一般,*,*,トウキョウト,東京都,*,B,0/1,*,0/1
+に,3,3,1000,に,助詞,格助詞,*,*,*,*,ニ,に,*,A,*,*,*
+行く,4,4,5105,行く,動詞,非自立可能,*,*,五段-カ行,終止形-一般,イク,行く,*,A,*,*,*
+`
+
+const testMatrix = `5 5
+0 0 0
+0 1 0
+0 2 0
+0 3 0
+0 4 0
+1 0 0
+1 1 0
+1 2 0
+1 3 0
+1 4 0
+2 0 0
+2 1 0
+2 2 0
+2 3 0
+2 4 0
+3 0 0
+3 1 0
+3 2 0
+3 3 0
+3 4 0
+4 0 0
+4 1 0
+4 2 0
+4 3 0
+4 4 0
+`
+
+// tokenize returns the morphemes of text, tokenized with the dictionary
+// of testLexicon. The characters not in it are OOVs of a character.
+func tokenize(t *testing.T, mode string, text string) *gosudachi.MorphemeList {
+	f, err := ioutil.TempFile("", "formatter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	hb, err := dictionary.NewDictionaryHeader(dictionary.SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dicbuilder := dictionary.NewDictionaryBuilder(int64(len(hb)), nil, false)
+	store := dictionary.NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(testLexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader(testMatrix), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	var id, cost int16 = 0, 10000
+	dict, err := gosudachi.NewJapaneseDictionary(
+		&gosudachi.BaseConfig{SystemDict: f.Name(), Storage: "heap"},
+		[]gosudachi.InputTextPlugin{},
+		[]gosudachi.OovProviderPlugin{
+			gosudachi.NewSimpleOovProviderPlugin(&gosudachi.SimpleOovProviderPluginConfig{
+				OovPos:  &oovPos,
+				LeftId:  &id,
+				RightId: &id,
+				Cost:    &cost,
+			}),
+		},
+		[]gosudachi.PathRewritePlugin{},
+		[]gosudachi.EditConnectionCostPlugin{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer dict.Close()
+
+	ms, err := dict.Create().Tokenize(mode, text)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return ms
+}
+
+func format(t *testing.T, f Formatter, mode string, lines ...string) string {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if err := f.Format(&buf, line, tokenize(t, mode, line)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := Finish(f, &buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return buf.String()
+}
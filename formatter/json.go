@@ -0,0 +1,118 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// JSONMorpheme is a morpheme in the json formats. Begin and End are the
+// offsets of the characters in the line.
+type JSONMorpheme struct {
+	Surface        string         `json:"surface"`
+	Begin          int            `json:"begin"`
+	End            int            `json:"end"`
+	POS            []string       `json:"pos"`
+	NormalizedForm string         `json:"normalizedForm"`
+	DictionaryForm string         `json:"dictionaryForm"`
+	ReadingForm    string         `json:"readingForm"`
+	DictionaryId   int            `json:"dictionaryId"`
+	WordId         int            `json:"wordId"`
+	OOV            bool           `json:"oov"`
+	SplitA         []JSONMorpheme `json:"splitA,omitempty"`
+	SplitB         []JSONMorpheme `json:"splitB,omitempty"`
+}
+
+// JSONSentence is the object written for a line.
+type JSONSentence struct {
+	Text      string         `json:"text"`
+	Morphemes []JSONMorpheme `json:"morphemes"`
+}
+
+// JSONFormatter writes an object for each line. The objects are the
+// elements of an indented array if array is true, otherwise each of them
+// is written in a line (JSON Lines).
+type JSONFormatter struct {
+	array  bool
+	splits bool
+	count  int
+}
+
+func NewJSONFormatter(array bool, splits bool) *JSONFormatter {
+	return &JSONFormatter{
+		array:  array,
+		splits: splits,
+	}
+}
+
+func newJSONMorphemes(ms *gosudachi.MorphemeList, splits bool) []JSONMorpheme {
+	ret := make([]JSONMorpheme, ms.Length())
+	for i := range ret {
+		m := ms.Get(i)
+		ret[i] = JSONMorpheme{
+			Surface:        m.Surface(),
+			Begin:          m.Begin(),
+			End:            m.End(),
+			POS:            m.PartOfSpeech(),
+			NormalizedForm: m.NormalizedForm(),
+			DictionaryForm: m.DictionaryForm(),
+			ReadingForm:    m.ReadingForm(),
+			DictionaryId:   m.GetDictionaryId(),
+			WordId:         m.GetWordId(),
+			OOV:            m.IsOOV(),
+		}
+		if splits {
+			ret[i].SplitA = newJSONMorphemes(m.Split("A"), false)
+			ret[i].SplitB = newJSONMorphemes(m.Split("B"), false)
+		}
+	}
+	return ret
+}
+
+// NewJSONSentence returns the object of a line.
+func NewJSONSentence(text string, ms *gosudachi.MorphemeList, splits bool) *JSONSentence {
+	return &JSONSentence{
+		Text:      text,
+		Morphemes: newJSONMorphemes(ms, splits),
+	}
+}
+
+func (f *JSONFormatter) Format(w io.Writer, text string, ms *gosudachi.MorphemeList) error {
+	if !f.array {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(NewJSONSentence(text, ms, f.splits))
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("  ", "  ")
+	if err := enc.Encode(NewJSONSentence(text, ms, f.splits)); err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if f.count == 0 {
+		sep = "[\n  "
+	}
+	f.count++
+	if _, err := io.WriteString(w, sep); err != nil {
+		return err
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func (f *JSONFormatter) Finish(w io.Writer) error {
+	if !f.array {
+		return nil
+	}
+	trailer := "\n]\n"
+	if f.count == 0 {
+		trailer = "[]\n"
+	}
+	_, err := io.WriteString(w, trailer)
+	return err
+}
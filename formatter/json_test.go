@@ -0,0 +1,86 @@
+package formatter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type jsonSpan struct {
+	surface    string
+	begin, end int
+}
+
+func jsonSpans(ms []JSONMorpheme) []jsonSpan {
+	ret := make([]jsonSpan, len(ms))
+	for i, m := range ms {
+		ret[i] = jsonSpan{m.Surface, m.Begin, m.End}
+	}
+	return ret
+}
+
+func TestJSONLFormatter(t *testing.T) {
+	text := "東京都に\t\"行く\""
+	got := format(t, NewJSONFormatter(false, false), "C", text, "に")
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("a line is expected for each sentence: %q", got)
+	}
+	if !strings.Contains(lines[0], `"text":"東京都に\t\"行く\""`) {
+		t.Errorf("the tab and the quotes are not escaped: %s", lines[0])
+	}
+	if strings.Contains(lines[0], "splitA") {
+		t.Errorf("splits are not expected: %s", lines[0])
+	}
+
+	var s JSONSentence
+	if err := json.Unmarshal([]byte(lines[0]), &s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s.Text != text {
+		t.Errorf("invalid text: want = %q, got = %q", text, s.Text)
+	}
+	want := []jsonSpan{
+		{"東京都", 0, 3},
+		{"に", 3, 4},
+		{"\t", 4, 5},
+		{"\"", 5, 6},
+		{"行く", 6, 8},
+		{"\"", 8, 9},
+	}
+	if got := jsonSpans(s.Morphemes); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid morphemes: want = %v, got = %v", want, got)
+	}
+	if m := s.Morphemes[2]; !m.OOV || m.DictionaryId != -1 {
+		t.Errorf("the tab must be an OOV: %v", m)
+	}
+	if m := s.Morphemes[0]; m.ReadingForm != "トウキョウト" || m.POS[2] != "地名" {
+		t.Errorf("invalid morpheme: %v", m)
+	}
+}
+
+func TestJSONFormatterSplits(t *testing.T) {
+	got := format(t, NewJSONFormatter(true, true), "C", "東京都", "行く")
+	var ss []JSONSentence
+	if err := json.Unmarshal([]byte(got), &ss); err != nil {
+		t.Fatalf("unexpected error: %s: %s", err, got)
+	}
+	if len(ss) != 2 || ss[0].Text != "東京都" || ss[1].Text != "行く" {
+		t.Fatalf("invalid sentences: %v", ss)
+	}
+	m := ss[0].Morphemes[0]
+	if want := []jsonSpan{{"東京", 0, 2}, {"都", 2, 3}}; !reflect.DeepEqual(jsonSpans(m.SplitA), want) {
+		t.Errorf("invalid splitA: want = %v, got = %v", want, jsonSpans(m.SplitA))
+	}
+	if want := []jsonSpan{{"東京都", 0, 3}}; !reflect.DeepEqual(jsonSpans(m.SplitB), want) {
+		t.Errorf("invalid splitB: want = %v, got = %v", want, jsonSpans(m.SplitB))
+	}
+	if m.SplitA[0].SplitA != nil {
+		t.Errorf("the splits must not be split: %v", m.SplitA[0])
+	}
+
+	if got := format(t, NewJSONFormatter(true, true), "C"); got != "[]\n" {
+		t.Errorf("an empty array is expected: %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+// DefaultMeCabFeatures is the layout of IPADIC: the parts of speech, the
+// conjugation type and form, the base form, the reading and the
+// pronunciation, for which the reading is used.
+var DefaultMeCabFeatures = []string{
+	"pos1", "pos2", "pos3", "pos4", "pos5", "pos6",
+	"dictionaryForm", "readingForm", "readingForm",
+}
+
+// MeCabFeatureNames lists the names of the feature columns.
+var MeCabFeatureNames = []string{
+	"pos1", "pos2", "pos3", "pos4", "pos5", "pos6",
+	"surface", "dictionaryForm", "normalizedForm", "readingForm",
+	"dictionaryId", "wordId",
+}
+
+// MeCabFormatter writes the surface and the comma separated features of
+// each morpheme, and "EOS" at the end of a line, as MeCab does.
+type MeCabFormatter struct {
+	features []string
+}
+
+// NewMeCabFormatter returns a MeCabFormatter writing features in order.
+// DefaultMeCabFeatures is used if features is empty.
+func NewMeCabFormatter(features []string) (*MeCabFormatter, error) {
+	if len(features) == 0 {
+		features = DefaultMeCabFeatures
+	}
+	for _, name := range features {
+		if !containsName(MeCabFeatureNames, name) {
+			return nil, fmt.Errorf("unknown feature: %s (%s)", name, strings.Join(MeCabFeatureNames, ", "))
+		}
+	}
+	return &MeCabFormatter{
+		features: features,
+	}, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mecabFeature returns the feature of m, or "*" if it is empty.
+func mecabFeature(m *gosudachi.Morpheme, name string) string {
+	var ret string
+	switch name {
+	case "surface":
+		ret = m.Surface()
+	case "dictionaryForm":
+		ret = m.DictionaryForm()
+	case "normalizedForm":
+		ret = m.NormalizedForm()
+	case "readingForm":
+		ret = m.ReadingForm()
+	case "dictionaryId":
+		ret = fmt.Sprint(m.GetDictionaryId())
+	case "wordId":
+		ret = fmt.Sprint(m.GetWordId())
+	default:
+		var level int
+		fmt.Sscanf(name, "pos%d", &level)
+		if 1 <= level && level <= dictionary.PosDepth {
+			ret = m.PartOfSpeech()[level-1]
+		}
+	}
+	if ret == "" {
+		return "*"
+	}
+	return ret
+}
+
+// escapeMeCabFeature quotes a feature containing a comma or a double
+// quote in the CSV style of the MeCab dictionaries.
+func escapeMeCabFeature(s string) string {
+	if !strings.ContainsAny(s, ",\"") {
+		return s
+	}
+	return "\"" + strings.Replace(s, "\"", "\"\"", -1) + "\""
+}
+
+func (f *MeCabFormatter) Format(w io.Writer, text string, ms *gosudachi.MorphemeList) error {
+	features := make([]string, len(f.features))
+	for i := 0; i < ms.Length(); i++ {
+		m := ms.Get(i)
+		for j, name := range f.features {
+			features[j] = escapeMeCabFeature(mecabFeature(m, name))
+		}
+		fmt.Fprintf(w, "%s\t%s\n", m.Surface(), strings.Join(features, ","))
+	}
+	_, err := fmt.Fprintln(w, "EOS")
+	return err
+}
+
+// WakatiFormatter writes the surfaces separated by spaces, as
+// mecab -Owakati does.
+type WakatiFormatter struct{}
+
+func NewWakatiFormatter() *WakatiFormatter {
+	return &WakatiFormatter{}
+}
+
+func (f *WakatiFormatter) Format(w io.Writer, text string, ms *gosudachi.MorphemeList) error {
+	surfaces := make([]string, ms.Length())
+	for i := range surfaces {
+		surfaces[i] = ms.GetSurface(i)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(surfaces, " "))
+	return err
+}
@@ -0,0 +1,53 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestMeCabFormatter(t *testing.T) {
+	f, err := NewMeCabFormatter(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := format(t, f, "C", "東京都に,行く\"")
+	want := "東京都\t名詞,固有名詞,地名,一般,*,*,東京都,トウキョウト,トウキョウト\n" +
+		"に\t助詞,格助詞,*,*,*,*,に,ニ,ニ\n" +
+		",\t名詞,普通名詞,一般,*,*,*,\",\",*,*\n" +
+		"行く\t動詞,非自立可能,*,*,五段-カ行,終止形-一般,行く,イク,イク\n" +
+		"\"\t名詞,普通名詞,一般,*,*,*,\"\"\"\",*,*\n" +
+		"EOS\n"
+	if got != want {
+		t.Errorf("invalid output: want = %q, got = %q", want, got)
+	}
+
+	f, err = NewMeCabFormatter([]string{"readingForm", "pos1", "surface", "dictionaryId", "wordId", "normalizedForm"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got = format(t, f, "A", "東京都", "")
+	want = "東京\tトウキョウ,名詞,東京,0,0,東京\n" +
+		"都\tト,名詞,都,0,1,都\n" +
+		"EOS\n" +
+		"EOS\n"
+	if got != want {
+		t.Errorf("invalid output: want = %q, got = %q", want, got)
+	}
+
+	if _, err := NewMeCabFormatter([]string{"pos1", "pos7"}); err == nil {
+		t.Error("error is expected for an unknown feature")
+	}
+	if _, err := New("mecab", &Options{MeCabFeatures: []string{"reading"}}); err == nil {
+		t.Error("error is expected for an unknown feature")
+	}
+}
+
+func TestWakatiFormatter(t *testing.T) {
+	got := format(t, NewWakatiFormatter(), "C", "東京都に行く", "")
+	if want := "東京都 に 行く\n\n"; got != want {
+		t.Errorf("invalid output: want = %q, got = %q", want, got)
+	}
+	got = format(t, NewWakatiFormatter(), "A", "東京都に行く")
+	if want := "東京 都 に 行く\n"; got != want {
+		t.Errorf("invalid output: want = %q, got = %q", want, got)
+	}
+}
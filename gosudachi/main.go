@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/msnoigrs/gosudachi/internal/command"
+)
+
+var commands = []*command.Command{
+	command.Tokenize,
+	command.Build,
+	command.BuildUser,
+	command.BuildMeCab,
+	command.Convert,
+	command.Print,
+	command.Header,
+	command.Serve,
+	command.Repl,
+	command.Bench,
+	command.Stats,
+	command.Config,
+}
+
+func main() {
+	command.Main("gosudachi", commands)
+}
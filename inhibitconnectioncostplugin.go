@@ -1,33 +1,95 @@
 package gosudachi
 
 import (
+	"fmt"
+
 	"github.com/msnoigrs/gosudachi/dictionary"
 )
 
+type InhibitConnectionPluginConfig struct {
+	InhibitedPair        *[][]int
+	InhibitedPatternPair *[]ConnectionPairConfig
+}
+
+// InhibitConnectionPlugin inhibits the connections given by the pairs of
+// a right id and a left id, or by the pairs of the patterns of the words,
+// which are resolved to the ids at SetUp.
 type InhibitConnectionPlugin struct {
-	inhibitedPair []*[]int
+	config  *InhibitConnectionPluginConfig
+	lexicon *dictionary.LexiconSet
+	left    [][]int16
+	right   [][]int16
 }
 
 func NewInhibitConnectionPlugin(inhibitedPair []*[]int) *InhibitConnectionPlugin {
+	config := &InhibitConnectionPluginConfig{}
+	if len(inhibitedPair) > 0 {
+		pairs := make([][]int, 0, len(inhibitedPair))
+		for _, pair := range inhibitedPair {
+			if pair != nil {
+				pairs = append(pairs, *pair)
+			}
+		}
+		config.InhibitedPair = &pairs
+	}
 	return &InhibitConnectionPlugin{
-		inhibitedPair: inhibitedPair,
+		config: config,
 	}
 }
 
 func (p *InhibitConnectionPlugin) GetConfigStruct() interface{} {
-	return p
+	if p.config == nil {
+		p.config = &InhibitConnectionPluginConfig{}
+	}
+	return p.config
+}
+
+func (p *InhibitConnectionPlugin) SetLexicon(lexicon *dictionary.LexiconSet) {
+	p.lexicon = lexicon
 }
 
 func (p *InhibitConnectionPlugin) SetUp(grammar *dictionary.Grammar) error {
+	p.left = [][]int16{}
+	p.right = [][]int16{}
+	if p.config.InhibitedPair != nil {
+		for i, pair := range *p.config.InhibitedPair {
+			if len(pair) < 2 {
+				continue
+			}
+			err := checkConnectionId(grammar, pair[0], true)
+			if err == nil {
+				err = checkConnectionId(grammar, pair[1], false)
+			}
+			if err != nil {
+				return fmt.Errorf("InhibitConnectionPlugin: %s in inhibitedPair[%d]", err, i)
+			}
+			p.left = append(p.left, []int16{int16(pair[0])})
+			p.right = append(p.right, []int16{int16(pair[1])})
+		}
+	}
+	if p.config.InhibitedPatternPair != nil {
+		resolver := newConnectionIdResolver(grammar, p.lexicon)
+		for i, pair := range *p.config.InhibitedPatternPair {
+			left, right, err := resolver.resolvePair(&pair)
+			if err != nil {
+				return fmt.Errorf("InhibitConnectionPlugin: %s in inhibitedPatternPair[%d]", err, i)
+			}
+			p.left = append(p.left, left)
+			p.right = append(p.right, right)
+		}
+	}
+	p.config = nil
+	p.lexicon = nil
 	return nil
 }
 
 func (p *InhibitConnectionPlugin) Edit(grammar *dictionary.Grammar) error {
-	for _, pair := range p.inhibitedPair {
-		if len(*pair) < 2 {
-			continue
+	for i := range p.left {
+		for _, leftId := range p.left[i] {
+			for _, rightId := range p.right[i] {
+				InhibitConnection(grammar, leftId, rightId)
+			}
 		}
-		InhibitConnection(grammar, int16((*pair)[0]), int16((*pair)[1]))
 	}
 	return nil
 }
@@ -11,6 +11,7 @@ type InputText struct {
 	ModifiedText             string
 	Bytea                    []byte
 	offsets                  []int
+	endOffsets               []int
 	byteIndexes              []int
 	charCategories           []uint32
 	charCategoryContinuities []int
@@ -50,6 +51,17 @@ func (t *InputText) GetOriginalIndex(index int) int {
 	return t.offsets[index]
 }
 
+// GetOriginalEndIndex returns the end in the original text of the
+// modified text ending at index. It differs from GetOriginalIndex when
+// the characters just before index replaced a text followed by a removed
+// one.
+func (t *InputText) GetOriginalEndIndex(index int) int {
+	if t.endOffsets == nil || index == 0 {
+		return t.offsets[index]
+	}
+	return t.endOffsets[index-1]
+}
+
 func (t *InputText) GetCharCategoryTypes(index int) uint32 {
 	return t.charCategories[t.byteIndexes[index]]
 }
@@ -96,10 +108,11 @@ func (t *InputText) IsCharAlignment(index int) bool {
 }
 
 type InputTextBuilder struct {
-	OriginalText  string
-	modifiedRunes []rune
-	textOffsets   []int
-	grammar       *dictionary.Grammar
+	OriginalText   string
+	modifiedRunes  []rune
+	textOffsets    []int
+	textEndOffsets []int
+	grammar        *dictionary.Grammar
 }
 
 func NewInputTextBuilder(text string, grammar *dictionary.Grammar) *InputTextBuilder {
@@ -110,11 +123,16 @@ func NewInputTextBuilder(text string, grammar *dictionary.Grammar) *InputTextBui
 		textOffsets[i] = i
 	}
 	textOffsets[len(modifiedRunes)] = len(modifiedRunes)
+	textEndOffsets := make([]int, len(modifiedRunes), len(modifiedRunes))
+	for i := 0; i < len(modifiedRunes); i++ {
+		textEndOffsets[i] = i + 1
+	}
 	return &InputTextBuilder{
-		OriginalText:  text,
-		modifiedRunes: modifiedRunes,
-		textOffsets:   textOffsets,
-		grammar:       grammar,
+		OriginalText:   text,
+		modifiedRunes:  modifiedRunes,
+		textOffsets:    textOffsets,
+		textEndOffsets: textEndOffsets,
+		grammar:        grammar,
 	}
 }
 
@@ -129,6 +147,10 @@ func (builder *InputTextBuilder) Replace(begin int, end int, runes []rune) {
 	tlen := end - begin
 
 	offset := builder.textOffsets[begin]
+	endOffset := offset
+	if end > begin {
+		endOffset = builder.textEndOffsets[end-1]
+	}
 
 	if rl < tlen {
 		ol := len(builder.modifiedRunes)
@@ -139,6 +161,10 @@ func (builder *InputTextBuilder) Replace(begin int, end int, runes []rune) {
 		tolen := len(builder.textOffsets)
 		copy(builder.textOffsets[begin+rl:], builder.textOffsets[end:])
 		builder.textOffsets = builder.textOffsets[:tolen-tlen+rl]
+
+		teolen := len(builder.textEndOffsets)
+		copy(builder.textEndOffsets[begin+rl:], builder.textEndOffsets[end:])
+		builder.textEndOffsets = builder.textEndOffsets[:teolen-tlen+rl]
 	} else if rl == tlen {
 		copy(builder.modifiedRunes[begin:], runes)
 	} else {
@@ -148,10 +174,14 @@ func (builder *InputTextBuilder) Replace(begin int, end int, runes []rune) {
 
 		builder.textOffsets = append(builder.textOffsets, make([]int, rl-tlen)...)
 		copy(builder.textOffsets[begin+rl:], builder.textOffsets[end:])
+
+		builder.textEndOffsets = append(builder.textEndOffsets, make([]int, rl-tlen)...)
+		copy(builder.textEndOffsets[begin+rl:], builder.textEndOffsets[end:])
 	}
 
 	for i := 0; i < rl; i++ {
 		builder.textOffsets[begin+i] = offset
+		builder.textEndOffsets[begin+i] = endOffset
 	}
 }
 
@@ -170,6 +200,7 @@ func (builder *InputTextBuilder) Build() *InputText {
 	size := bytelength + 1
 	indexes := make([]int, size, size)
 	offsets := make([]int, size, size)
+	endOffsets := make([]int, bytelength, bytelength)
 
 	sizes := make([]int, runeCount, runeCount)
 
@@ -180,6 +211,7 @@ func (builder *InputTextBuilder) Build() *InputText {
 		for j := 0; j < size; j++ {
 			indexes[pi] = i
 			offsets[pi] = builder.textOffsets[i]
+			endOffsets[pi] = builder.textEndOffsets[i]
 			pi++
 		}
 		p = p[size:]
@@ -220,7 +252,7 @@ func (builder *InputTextBuilder) Build() *InputText {
 		}
 	}
 
-	return &InputText{
+	inputText := NewInputText(
 		builder.OriginalText,
 		modifiedText,
 		keepp,
@@ -229,7 +261,9 @@ func (builder *InputTextBuilder) Build() *InputText {
 		charCategoryTypes,
 		charCategoryContinuities,
 		canBowList,
-	}
+	)
+	inputText.endOffsets = endOffsets
+	return inputText
 }
 
 func getCharCategoryContinuousLength(charCategories []uint32, offset int) int {
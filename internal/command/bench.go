@@ -0,0 +1,269 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// Bench is the command measuring the throughput and the latency of
+// tokenizing a corpus.
+var Bench = &Command{
+	Name:    "bench",
+	Usage:   "[-r file|-s jsonstring] [-p dir] [-j] [-m A|B|C] [-n iterations|-t duration] [-P n] [-cpuprofile file] [-memprofile file] [-json] file ...",
+	Summary: "measure the throughput and the latency of tokenizing a corpus",
+	Run:     runBench,
+}
+
+// BenchResult is the report of the bench command. The durations are in
+// nanoseconds in JSON.
+type BenchResult struct {
+	Mode              string        `json:"mode"`
+	Parallelism       int           `json:"parallelism"`
+	LoadTime          time.Duration `json:"loadTimeNs"`
+	Elapsed           time.Duration `json:"elapsedNs"`
+	Sentences         int64         `json:"sentences"`
+	Characters        int64         `json:"characters"`
+	Errors            int64         `json:"errors"`
+	SentencesPerSec   float64       `json:"sentencesPerSec"`
+	CharactersPerSec  float64       `json:"charactersPerSec"`
+	LatencyP50        time.Duration `json:"latencyP50Ns"`
+	LatencyP90        time.Duration `json:"latencyP90Ns"`
+	LatencyP99        time.Duration `json:"latencyP99Ns"`
+	LatencyMax        time.Duration `json:"latencyMaxNs"`
+	AllocsPerSentence float64       `json:"allocsPerSentence"`
+	BytesPerSentence  float64       `json:"bytesPerSentence"`
+}
+
+func readCorpus(files []string) ([]string, error) {
+	corpus := []string{}
+	for _, file := range files {
+		input, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		s := NewLineScanner(input)
+		for s.Scan() {
+			if line := s.Text(); line != "" {
+				corpus = append(corpus, line)
+			}
+		}
+		input.Close()
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+	}
+	if len(corpus) == 0 {
+		return nil, fmt.Errorf("the corpus is empty")
+	}
+	return corpus, nil
+}
+
+// percentile returns the p-th percentile of sorted latencies.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(latencies))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(latencies) {
+		i = len(latencies) - 1
+	}
+	return latencies[i]
+}
+
+// bench tokenizes the sentences of corpus iterations times, or repeatedly
+// for duration if it is positive, with the tokenizers in parallel, and
+// sets the measurements to result.
+func bench(tokenizers []*gosudachi.JapaneseTokenizer, mode string, corpus []string, iterations int, duration time.Duration, result *BenchResult) {
+	parallelism := len(tokenizers)
+	var (
+		next       int64 = -1
+		total            = int64(iterations * len(corpus))
+		deadline   time.Time
+		wg         sync.WaitGroup
+		latencies  = make([][]time.Duration, parallelism)
+		characters = make([]int64, parallelism)
+		errorCount = make([]int64, parallelism)
+		before     runtime.MemStats
+		after      runtime.MemStats
+	)
+	if iterations > 0 {
+		for w := range latencies {
+			latencies[w] = make([]time.Duration, 0, total/int64(parallelism)+1)
+		}
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	if duration > 0 {
+		deadline = start.Add(duration)
+	}
+	for w, tokenizer := range tokenizers {
+		wg.Add(1)
+		go func(w int, tokenizer *gosudachi.JapaneseTokenizer) {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt64(&next, 1)
+				if duration > 0 {
+					if time.Now().After(deadline) {
+						return
+					}
+				} else if i >= total {
+					return
+				}
+				text := corpus[i%int64(len(corpus))]
+				t := time.Now()
+				_, err := tokenizer.Tokenize(mode, text)
+				latencies[w] = append(latencies[w], time.Since(t))
+				characters[w] += int64(utf8.RuneCountInString(text))
+				if err != nil {
+					errorCount[w]++
+				}
+			}
+		}(w, tokenizer)
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	all := []time.Duration{}
+	for w := range latencies {
+		all = append(all, latencies[w]...)
+		result.Characters += characters[w]
+		result.Errors += errorCount[w]
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	result.Sentences = int64(len(all))
+	seconds := result.Elapsed.Seconds()
+	if seconds > 0 {
+		result.SentencesPerSec = float64(result.Sentences) / seconds
+		result.CharactersPerSec = float64(result.Characters) / seconds
+	}
+	result.LatencyP50 = percentile(all, 50)
+	result.LatencyP90 = percentile(all, 90)
+	result.LatencyP99 = percentile(all, 99)
+	if len(all) > 0 {
+		result.LatencyMax = all[len(all)-1]
+		result.AllocsPerSentence = float64(after.Mallocs-before.Mallocs) / float64(len(all))
+		result.BytesPerSentence = float64(after.TotalAlloc-before.TotalAlloc) / float64(len(all))
+	}
+}
+
+func runBench(fs *flag.FlagSet, args []string) error {
+	var (
+		dictoptions DictionaryOptions
+		mode        string
+		iterations  int
+		duration    time.Duration
+		parallelism int
+		cpuprofile  string
+		memprofile  string
+		jsonoutput  bool
+	)
+	dictoptions.SetFlags(fs)
+	fs.StringVar(&mode, "m", "C", "mode of splitting")
+	fs.IntVar(&iterations, "n", 0, "number of passes over the corpus (default 1 unless -t is given)")
+	fs.DurationVar(&duration, "t", 0, "duration to run, repeating the corpus")
+	fs.IntVar(&parallelism, "P", 1, "number of goroutines tokenizing the sentences")
+	fs.StringVar(&cpuprofile, "cpuprofile", "", "write a CPU profile of the run to file")
+	fs.StringVar(&memprofile, "memprofile", "", "write a heap profile after the run to file")
+	fs.BoolVar(&jsonoutput, "json", false, "print the result in JSON")
+	fs.Parse(args)
+
+	if !gosudachi.ValidMode(mode) {
+		return fmt.Errorf("invalid mode: %s", mode)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("no corpus file is given")
+	}
+	if iterations > 0 && duration > 0 {
+		return fmt.Errorf("-n and -t cannot be used together")
+	}
+	if iterations <= 0 && duration <= 0 {
+		iterations = 1
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	corpus, err := readCorpus(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	dict, err := LoadDictionary(&dictoptions)
+	if err != nil {
+		return err
+	}
+	defer dict.Close()
+	result := &BenchResult{
+		Mode:        mode,
+		Parallelism: parallelism,
+		LoadTime:    time.Since(start),
+	}
+
+	tokenizers := make([]*gosudachi.JapaneseTokenizer, parallelism)
+	for i := range tokenizers {
+		tokenizers[i] = dict.Create()
+	}
+
+	if cpuprofile != "" {
+		f, err := os.Create(cpuprofile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+	}
+
+	bench(tokenizers, mode, corpus, iterations, duration, result)
+	if cpuprofile != "" {
+		pprof.StopCPUProfile()
+	}
+
+	if memprofile != "" {
+		f, err := os.Create(memprofile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return err
+		}
+	}
+
+	if jsonoutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	fmt.Printf("mode:                %s\n", result.Mode)
+	fmt.Printf("parallelism:         %d\n", result.Parallelism)
+	fmt.Printf("dictionary load:     %s\n", result.LoadTime)
+	fmt.Printf("elapsed:             %s\n", result.Elapsed)
+	fmt.Printf("sentences:           %d (%d errors)\n", result.Sentences, result.Errors)
+	fmt.Printf("characters:          %d\n", result.Characters)
+	fmt.Printf("sentences/sec:       %.1f\n", result.SentencesPerSec)
+	fmt.Printf("characters/sec:      %.1f\n", result.CharactersPerSec)
+	fmt.Printf("latency p50/p90/p99: %s / %s / %s\n", result.LatencyP50, result.LatencyP90, result.LatencyP99)
+	fmt.Printf("latency max:         %s\n", result.LatencyMax)
+	fmt.Printf("allocs/sentence:     %.1f\n", result.AllocsPerSentence)
+	fmt.Printf("bytes/sentence:      %.1f\n", result.BytesPerSentence)
+	return nil
+}
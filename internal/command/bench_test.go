@@ -0,0 +1,73 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 200)
+	for i := range latencies {
+		latencies[i] = time.Duration(i + 1)
+	}
+	tests := []struct {
+		latencies []time.Duration
+		p         float64
+		want      time.Duration
+	}{
+		{latencies, 50, 100},
+		{latencies, 90, 180},
+		{latencies, 99, 198},
+		{latencies, 100, 200},
+		{latencies, 0, 1},
+		{latencies[:1], 99, 1},
+		{latencies[:3], 50, 2},
+		{nil, 50, 0},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.latencies, tt.p); got != tt.want {
+			t.Errorf("%d latencies, p%v: want = %d, got = %d", len(tt.latencies), tt.p, tt.want, got)
+		}
+	}
+}
+
+func TestBench(t *testing.T) {
+	f, err := ioutil.TempFile("", "bench")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("東京都に行く\n\n失敗\n行く\n")
+	f.Close()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	corpus, err := readCorpus([]string{f.Name()})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(corpus) != 3 {
+		t.Fatalf("the empty line is read: %v", corpus)
+	}
+
+	dict := newTestDictionary(t, &failingPlugin{})
+	defer dict.Close()
+	tokenizers := []*gosudachi.JapaneseTokenizer{dict.Create(), dict.Create()}
+	result := &BenchResult{}
+	bench(tokenizers, "C", corpus, 1, 0, result)
+	if result.Sentences != 3 || result.Characters != 10 || result.Errors != 1 {
+		t.Errorf("want = 3 sentences, 10 characters and 1 error, got = %d, %d and %d", result.Sentences, result.Characters, result.Errors)
+	}
+	if result.LatencyP50 <= 0 || result.LatencyP50 > result.LatencyP90 || result.LatencyP90 > result.LatencyP99 || result.LatencyP99 > result.LatencyMax {
+		t.Errorf("invalid latencies: %s, %s, %s, %s", result.LatencyP50, result.LatencyP90, result.LatencyP99, result.LatencyMax)
+	}
+
+	if err := runBench(Bench.NewFlagSet("bench"), []string{"-n", "1"}); err == nil {
+		t.Error("error is expected without a corpus file")
+	}
+}
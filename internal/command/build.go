@@ -0,0 +1,431 @@
+package command
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/transform"
+)
+
+// Build is the command building a system dictionary.
+var Build = &Command{
+	Name:    "build",
+	Usage:   "-o file -m file [-d description] [-M key=value ...] [-j] [-v] [-P n] file1 [file2 ...]",
+	Summary: "build a system dictionary from the lexicon CSV files",
+	Run:     runBuild,
+}
+
+// BuildUser is the command building a user dictionary.
+var BuildUser = &Command{
+	Name:    "build-user",
+	Usage:   "-o file -s file [-d description] [-M key=value ...] [-j] [-v] [-P n] file1 [file2 ...]",
+	Summary: "build a user dictionary from the lexicon CSV files",
+	Run:     runBuildUser,
+}
+
+// BuildMeCab is the command building a dictionary from the source
+// files of a MeCab dictionary.
+var BuildMeCab = &Command{
+	Name: "build-mecab",
+	Usage: `-o file (-m file|-s file) [-L file -R file] [-t ipadic|unidic] [-p file] [-e encoding] [-n] [-c file] [-d description] [-M key=value ...] [-j] [-v] [-P n] file1 [file2 ...]
+	-c file [-L file -R file] [-t ipadic|unidic] [-p file] [-e encoding] [-n] file1 [file2 ...]`,
+	Summary: "build a dictionary from the source files of a MeCab dictionary",
+	Run:     runBuildMeCab,
+}
+
+// buildOptions are the options shared by the commands building a
+// dictionary.
+type buildOptions struct {
+	outputpath  string
+	description string
+	metadata    *dictionary.DictionaryMetadata
+	utf16string bool
+	verbose     bool
+	parallelism int
+}
+
+func (o *buildOptions) setFlags(fs *flag.FlagSet) {
+	o.metadata = dictionary.NewDictionaryMetadata()
+	fs.StringVar(&o.outputpath, "o", "", "output to file")
+	fs.StringVar(&o.description, "d", "", "comment")
+	fs.Var(o.metadata, "M", "metadata key=value (repeatable)")
+	setUTF16Flag(fs, &o.utf16string, "use UTF-16 string")
+	fs.BoolVar(&o.verbose, "v", false, "report entry counts by part of speech")
+	fs.IntVar(&o.parallelism, "P", runtime.NumCPU(), "number of goroutines parsing the source files")
+}
+
+// dictionaryWriter writes a dictionary built by a DictionaryBuilder.
+type dictionaryWriter struct {
+	header     *dictionary.DictionaryHeader
+	output     *os.File
+	dicbuilder *dictionary.DictionaryBuilder
+	verbose    bool
+}
+
+// create writes the header of a dictionary of version to the output
+// file, and returns the dictionaryWriter whose DictionaryBuilder
+// continues it. systemLexicon is nil for a system dictionary.
+func (o *buildOptions) create(version uint64, tool string, systemLexicon *dictionary.DoubleArrayLexicon) (*dictionaryWriter, error) {
+	dh := dictionary.NewDictionaryHeader(
+		version,
+		time.Now().Unix(),
+		o.description,
+	)
+	o.metadata.PutDefault(dictionary.MetadataBuildTool, dictionary.BuildToolVersion(tool))
+	o.metadata.PutDefault(dictionary.MetadataEncoding, dictionary.StringEncoding(o.utf16string))
+	dh.Metadata = o.metadata
+
+	hb, err := dh.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	outputWriter, err := os.OpenFile(o.outputpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", o.outputpath, err)
+	}
+
+	bufout := bufio.NewWriter(outputWriter)
+	n, err := bufout.Write(hb)
+	if err == nil {
+		err = bufout.Flush()
+	}
+	if err != nil {
+		outputWriter.Close()
+		return nil, fmt.Errorf("fail to write header: %s", err)
+	}
+
+	dicbuilder := dictionary.NewDictionaryBuilder(int64(n), systemLexicon, o.utf16string)
+	dicbuilder.SetProgressFunc(dictionary.NewBuildProgressPrinter(os.Stderr, dicbuilder.Stats()))
+	dicbuilder.SetParallelism(o.parallelism)
+	return &dictionaryWriter{
+		header:     dh,
+		output:     outputWriter,
+		dicbuilder: dicbuilder,
+		verbose:    o.verbose,
+	}, nil
+}
+
+// finish writes the lexicon and the metadata, and closes the output.
+func (w *dictionaryWriter) finish(store dictionary.PosIdStore) error {
+	defer w.output.Close()
+
+	err := w.dicbuilder.WriteLexicon(w.output, store)
+	if err != nil {
+		return fmt.Errorf("fail to write lexicon: %s", err)
+	}
+
+	_, err = w.header.WriteMetadataTo(w.output)
+	if err != nil {
+		return fmt.Errorf("fail to write metadata: %s", err)
+	}
+
+	dictionary.PrintBuildStats(w.dicbuilder.Stats(), w.verbose, os.Stderr)
+	return nil
+}
+
+func buildLexicons(dicbuilder *dictionary.DictionaryBuilder, store dictionary.PosIdStore, lexiconpaths []string) error {
+	fmt.Fprint(os.Stderr, "reading the source file...")
+	for _, lexiconpath := range lexiconpaths {
+		err := buildLexicon(dicbuilder, store, lexiconpath)
+		if err != nil {
+			return fmt.Errorf("%s: %s", lexiconpath, err)
+		}
+	}
+	p := message.NewPrinter(language.English)
+	p.Fprintf(os.Stderr, " %d words\n", dicbuilder.EntrySize())
+	return nil
+}
+
+func buildLexicon(dicbuilder *dictionary.DictionaryBuilder, store dictionary.PosIdStore, lexiconpath string) error {
+	lexiconReader, err := os.OpenFile(lexiconpath, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer lexiconReader.Close()
+
+	return dicbuilder.BuildLexicon(store, lexiconReader)
+}
+
+func runBuild(fs *flag.FlagSet, args []string) error {
+	var (
+		options    buildOptions
+		matrixpath string
+	)
+	options.setFlags(fs)
+	fs.StringVar(&matrixpath, "m", "", "connection matrix file")
+	fs.Parse(args)
+
+	if options.outputpath == "" || matrixpath == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	matrixReader, err := os.OpenFile(matrixpath, os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%s: %s", matrixpath, err)
+	}
+	defer matrixReader.Close()
+
+	w, err := options.create(dictionary.SystemDictVersion, "build", nil)
+	if err != nil {
+		return err
+	}
+	store := dictionary.NewPosTable()
+
+	err = buildLexicons(w.dicbuilder, store, fs.Args())
+	if err != nil {
+		w.output.Close()
+		return err
+	}
+
+	err = w.dicbuilder.WriteGrammar(store, matrixReader, w.output)
+	if err != nil {
+		w.output.Close()
+		return fmt.Errorf("fail to write grammar: %s", err)
+	}
+	return w.finish(store)
+}
+
+func runBuildUser(fs *flag.FlagSet, args []string) error {
+	var (
+		options    buildOptions
+		systemdict string
+	)
+	options.setFlags(fs)
+	fs.StringVar(&systemdict, "s", "", "system dictionary")
+	fs.Parse(args)
+
+	if options.outputpath == "" || systemdict == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	sdic, err := dictionary.ReadSystemDictionary(systemdict, options.utf16string)
+	if err != nil {
+		return err
+	}
+	defer sdic.Close()
+
+	w, err := options.create(dictionary.UserDictVersion2, "build-user", sdic.Lexicon)
+	if err != nil {
+		return err
+	}
+	store := dictionary.NewPosTableUser(sdic.Grammar)
+
+	err = buildLexicons(w.dicbuilder, store, fs.Args())
+	if err != nil {
+		w.output.Close()
+		return err
+	}
+
+	err = w.dicbuilder.WriteGrammarUser(&store.PosTable, w.output)
+	if err != nil {
+		w.output.Close()
+		return fmt.Errorf("fail to write grammar: %s", err)
+	}
+	return w.finish(store)
+}
+
+func runBuildMeCab(fs *flag.FlagSet, args []string) error {
+	var (
+		options     buildOptions
+		matrixpath  string
+		systemdict  string
+		leftidpath  string
+		rightidpath string
+		format      string
+		posmappath  string
+		encoding    string
+		nonormalize bool
+		csvpath     string
+	)
+	options.setFlags(fs)
+	fs.StringVar(&matrixpath, "m", "", "connection matrix file (builds a system dictionary)")
+	fs.StringVar(&systemdict, "s", "", "system dictionary (builds a user dictionary)")
+	fs.StringVar(&leftidpath, "L", "", "left-id.def (validates the left IDs)")
+	fs.StringVar(&rightidpath, "R", "", "right-id.def (validates the right IDs)")
+	fs.StringVar(&format, "t", "ipadic", "format of the source files: ipadic or unidic")
+	fs.StringVar(&posmappath, "p", "", "part-of-speech mapping table")
+	fs.StringVar(&encoding, "e", "utf8", "encoding of the source files: utf8, eucjp or sjis")
+	fs.BoolVar(&nonormalize, "n", false, "do not normalize headwords")
+	fs.StringVar(&csvpath, "c", "", "write the converted source to file")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 ||
+		(options.outputpath == "" && csvpath == "") ||
+		(options.outputpath != "" && (matrixpath == "") == (systemdict == "")) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	columns, err := dictionary.GetMeCabColumns(format)
+	if err != nil {
+		return err
+	}
+
+	var posMap *dictionary.MeCabPosMap
+	if posmappath != "" {
+		posMap, err = readPosMap(posmappath)
+		if err != nil {
+			return fmt.Errorf("%s: %s", posmappath, err)
+		}
+	}
+
+	conv := dictionary.NewMeCabConverter(columns, posMap)
+	conv.NormalizeHeadword = !nonormalize
+	if leftidpath != "" {
+		conv.LeftIdSize, err = readIdDef(leftidpath)
+		if err != nil {
+			return fmt.Errorf("%s: %s", leftidpath, err)
+		}
+	}
+	if rightidpath != "" {
+		conv.RightIdSize, err = readIdDef(rightidpath)
+		if err != nil {
+			return fmt.Errorf("%s: %s", rightidpath, err)
+		}
+	}
+
+	fmt.Fprint(os.Stderr, "reading the MeCab source file...")
+	for _, lexiconpath := range fs.Args() {
+		err := readMeCabLexicon(conv, lexiconpath, encoding)
+		if err != nil {
+			return fmt.Errorf("%s: %s", lexiconpath, err)
+		}
+	}
+	p := message.NewPrinter(language.English)
+	p.Fprintf(os.Stderr, " %d words\n", conv.EntrySize())
+
+	if csvpath != "" {
+		err := writeCsv(conv, csvpath)
+		if err != nil {
+			return fmt.Errorf("%s: %s", csvpath, err)
+		}
+	}
+	if options.outputpath == "" {
+		return nil
+	}
+
+	if systemdict == "" {
+		matrixReader, err := os.OpenFile(matrixpath, os.O_RDONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("%s: %s", matrixpath, err)
+		}
+		defer matrixReader.Close()
+
+		w, err := options.create(dictionary.SystemDictVersion, "build-mecab", nil)
+		if err != nil {
+			return err
+		}
+		store := dictionary.NewPosTable()
+
+		err = w.dicbuilder.BuildLexiconFromMeCab(store, conv)
+		if err != nil {
+			w.output.Close()
+			return fmt.Errorf("fail to build lexicon: %s", err)
+		}
+
+		err = w.dicbuilder.WriteGrammar(store, matrixReader, w.output)
+		if err != nil {
+			w.output.Close()
+			return fmt.Errorf("fail to write grammar: %s", err)
+		}
+		return w.finish(store)
+	}
+
+	sdic, err := dictionary.ReadSystemDictionary(systemdict, options.utf16string)
+	if err != nil {
+		return err
+	}
+	defer sdic.Close()
+
+	w, err := options.create(dictionary.UserDictVersion2, "build-mecab", sdic.Lexicon)
+	if err != nil {
+		return err
+	}
+	store := dictionary.NewPosTableUser(sdic.Grammar)
+
+	err = w.dicbuilder.BuildLexiconFromMeCab(store, conv)
+	if err != nil {
+		w.output.Close()
+		return fmt.Errorf("fail to build lexicon: %s", err)
+	}
+
+	err = w.dicbuilder.WriteGrammarUser(&store.PosTable, w.output)
+	if err != nil {
+		w.output.Close()
+		return fmt.Errorf("fail to write grammar: %s", err)
+	}
+	return w.finish(store)
+}
+
+func newDecodeReader(r io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "utf8", "utf-8":
+		return r, nil
+	case "eucjp", "euc-jp":
+		return transform.NewReader(r, japanese.EUCJP.NewDecoder()), nil
+	case "sjis", "shift_jis", "cp932":
+		return transform.NewReader(r, japanese.ShiftJIS.NewDecoder()), nil
+	}
+	return nil, fmt.Errorf("%s is unknown encoding", encoding)
+}
+
+func readMeCabLexicon(conv *dictionary.MeCabConverter, lexiconpath string, encoding string) error {
+	lexiconReader, err := os.OpenFile(lexiconpath, os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer lexiconReader.Close()
+
+	r, err := newDecodeReader(lexiconReader, encoding)
+	if err != nil {
+		return err
+	}
+	return conv.Read(r)
+}
+
+func readPosMap(posmappath string) (*dictionary.MeCabPosMap, error) {
+	posMapReader, err := os.OpenFile(posmappath, os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer posMapReader.Close()
+
+	posMap := dictionary.NewMeCabPosMap()
+	err = posMap.ReadPosMap(posMapReader)
+	if err != nil {
+		return nil, err
+	}
+	return posMap, nil
+}
+
+func readIdDef(idpath string) (int, error) {
+	idReader, err := os.OpenFile(idpath, os.O_RDONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer idReader.Close()
+
+	return dictionary.ReadMeCabIdDef(idReader)
+}
+
+func writeCsv(conv *dictionary.MeCabConverter, csvpath string) error {
+	csvWriter, err := os.OpenFile(csvpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer csvWriter.Close()
+
+	return conv.WriteLexicon(csvWriter, 0)
+}
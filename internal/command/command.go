@@ -0,0 +1,94 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Command is a subcommand of a multi-command binary.
+type Command struct {
+	Name string
+	// Usage is the arguments following the name. The lines of a
+	// command having several forms are separated by "\n\t".
+	Usage   string
+	Summary string
+	// Run parses the arguments following the name with fs, which exits
+	// on an error, and runs the command. The name of fs is the one used
+	// in the messages, such as "gosudachi build" or "dicbuilder".
+	Run func(fs *flag.FlagSet, args []string) error
+}
+
+// NewFlagSet returns the FlagSet of c named name whose usage message
+// starts with the usage lines of c.
+func (c *Command) NewFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage of %s:
+	%s %s
+
+Options:
+`, name, name, strings.Replace(c.Usage, "\n\t", "\n\t"+name+" ", -1))
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *Command) run(name string, args []string) {
+	fs := c.NewFlagSet(name)
+	err := c.Run(fs, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// Main runs the command named by the first argument, and exits.
+func Main(program string, commands []*Command) {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n\t%s command [options] [arguments]\n\nCommands:\n", program, program)
+		for _, c := range commands {
+			fmt.Fprintf(os.Stderr, "\t%-12s %s\n", c.Name, c.Summary)
+		}
+		fmt.Fprintf(os.Stderr, "\nRun '%s help command' for the options of a command.\n", program)
+	}
+	find := func(name string) *Command {
+		for _, c := range commands {
+			if c.Name == name {
+				return c
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s: unknown command %s\n", program, name)
+		usage()
+		os.Exit(2)
+		return nil
+	}
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		if name == "help" && len(os.Args) > 2 {
+			// the flags are defined by Run
+			find(os.Args[2]).run(program+" "+os.Args[2], []string{"-h"})
+		}
+		usage()
+		os.Exit(0)
+	}
+	find(name).run(program+" "+name, os.Args[2:])
+}
+
+// Single runs c as the whole of the program, and exits. It lets the
+// single-command binaries share the code of the subcommands.
+func Single(program string, c *Command) {
+	c.run(program, os.Args[1:])
+}
+
+// setUTF16Flag defines the -j flag, which selects UTF-16 strings in
+// the dictionaries, in fs.
+func setUTF16Flag(fs *flag.FlagSet, p *bool, usage string) {
+	fs.BoolVar(p, "j", false, usage)
+}
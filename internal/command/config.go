@@ -0,0 +1,49 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// Config is the command checking the settings.
+var Config = &Command{
+	Name:    "config",
+	Usage:   "check [-r file|-s jsonstring] [-p dir] [-j]",
+	Summary: "check the settings and print the effective settings",
+	Run:     runConfig,
+}
+
+func runConfig(fs *flag.FlagSet, args []string) error {
+	var dictoptions DictionaryOptions
+	dictoptions.SetFlags(fs)
+	if len(args) == 0 || args[0] != "check" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+
+	settings, _, err := dictoptions.parseSettings()
+	if err != nil {
+		return err
+	}
+
+	effective, problems := gosudachi.CheckSettings(settings.(*gosudachi.SettingsJSON))
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(effective)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Convert is the command converting the string encoding of a
+// dictionary between UTF-8 and UTF-16.
+var Convert = &Command{
+	Name:    "convert",
+	Usage:   "[-o file] [-j] file",
+	Summary: "convert a dictionary between UTF-8 and UTF-16 strings",
+	Run:     runConvert,
+}
+
+func runConvert(fs *flag.FlagSet, args []string) error {
+	var (
+		outputfile  string
+		utf16string bool
+	)
+	fs.StringVar(&outputfile, "o", "", "output to file")
+	setUTF16Flag(fs, &utf16string, "from UTF-8 to UTF-16")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if outputfile == "" {
+		if utf16string {
+			outputfile = "out_utf16.dic"
+		} else {
+			outputfile = "out_utf8.dic"
+		}
+	}
+	if !filepath.IsAbs(outputfile) {
+		var err error
+		outputfile, err = filepath.Abs(outputfile)
+		if err != nil {
+			return err
+		}
+	}
+
+	fromdic, err := dictionary.NewBinaryDictionary(fs.Arg(0), !utf16string)
+	if err != nil {
+		return err
+	}
+	defer fromdic.Close()
+
+	outputfd, err := os.OpenFile(outputfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("%s: %s", outputfile, err)
+	}
+	defer outputfd.Close()
+
+	return convertDictionary(fromdic, outputfd, utf16string)
+}
+
+func convertDictionary(fromdic *dictionary.BinaryDictionary, outputfd *os.File, utf16string bool) error {
+	bufiooutput := bufio.NewWriter(outputfd)
+
+	hb, err := fromdic.Header.ToBytes()
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	n, err := bufiooutput.Write(hb)
+	if err != nil {
+		return fmt.Errorf("fail to write header: %s", err)
+	}
+	offset = int64(n)
+
+	var n64 int64
+	p := message.NewPrinter(language.English)
+	if fromdic.Grammar != nil {
+		fmt.Fprint(os.Stderr, "writting the POS table...")
+		buffer := bytes.NewBuffer([]byte{})
+		err = fromdic.Grammar.WritePOSTableTo(buffer, utf16string)
+		if err != nil {
+			return err
+		}
+		n64, err = buffer.WriteTo(bufiooutput)
+		if err != nil {
+			return err
+		}
+		p.Fprintf(os.Stderr, " %d bytes\n", n64)
+		buffer.Reset()
+		offset += n64
+
+		fmt.Fprint(os.Stderr, "writting the connection matrix...")
+		n, err = fromdic.Grammar.WriteConnMatrixTo(bufiooutput)
+		if err != nil {
+			return err
+		}
+		p.Fprintf(os.Stderr, " %d bytes\n", n)
+		offset += int64(n)
+	}
+
+	fmt.Fprint(os.Stderr, "writting the trie...")
+	n, err = fromdic.Lexicon.WriteTrieTo(bufiooutput)
+	if err != nil {
+		return err
+	}
+	p.Fprintf(os.Stderr, " %d bytes\n", n)
+	offset += int64(n)
+
+	fmt.Fprint(os.Stderr, "writting the word-ID table...")
+	n, err = fromdic.Lexicon.WriteWordIdTableTo(bufiooutput)
+	if err != nil {
+		return err
+	}
+	p.Fprintf(os.Stderr, " %d bytes\n", n)
+	offset += int64(n)
+
+	fmt.Fprint(os.Stderr, "writting the word parameters...")
+	n, err = fromdic.Lexicon.WriteWordParamsTo(bufiooutput)
+	if err != nil {
+		return err
+	}
+	p.Fprintf(os.Stderr, " %d bytes\n", n)
+	offset += int64(n)
+
+	err = bufiooutput.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stderr, "writting the wordInfos...")
+	offsetlen := int64(4 * fromdic.Lexicon.Size())
+	_, err = outputfd.Seek(offsetlen, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	bufiooutput = bufio.NewWriter(outputfd)
+
+	n, offsets, err := fromdic.Lexicon.WriteWordInfos(bufiooutput, offset, offsetlen, utf16string)
+	if err != nil {
+		return err
+	}
+	p.Fprintf(os.Stderr, " %d bytes\n", n)
+
+	err = bufiooutput.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stderr, "writting wordInfo offsets...")
+	_, err = outputfd.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	bufiooutput = bufio.NewWriter(outputfd)
+
+	n64, err = offsets.WriteTo(bufiooutput)
+	if err != nil {
+		return err
+	}
+	p.Fprintf(os.Stderr, " %d bytes\n", n64)
+
+	err = bufiooutput.Flush()
+	if err != nil {
+		return err
+	}
+
+	if fromdic.Header.Metadata != nil {
+		fmt.Fprint(os.Stderr, "writting the metadata...")
+		fromdic.Header.Metadata.Put(dictionary.MetadataEncoding, dictionary.StringEncoding(utf16string))
+		n, err = fromdic.Header.WriteMetadataTo(outputfd)
+		if err != nil {
+			return err
+		}
+		p.Fprintf(os.Stderr, " %d bytes\n", n)
+	}
+	return nil
+}
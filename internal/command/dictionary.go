@@ -0,0 +1,132 @@
+// Package command has the code shared by the commands.
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/data"
+)
+
+// DictionaryOptions are the options of the commands loading a
+// JapaneseDictionary.
+type DictionaryOptions struct {
+	SettingFile   string
+	MergeSettings string
+	ResourcesDir  string
+	Utf16String   bool
+}
+
+// SetFlags defines the flags of the options in fs.
+func (o *DictionaryOptions) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.SettingFile, "r", "", "read settings from file (overrides -s)")
+	fs.StringVar(&o.MergeSettings, "s", "", "additional settings (overrides -r)")
+	fs.StringVar(&o.ResourcesDir, "p", "", "root directory of resources")
+	setUTF16Flag(fs, &o.Utf16String, "use UTF-16 string")
+}
+
+// parseSettings reads the settings of o. The resources are looked up in
+// the directory of the executable unless ResourcesDir is given.
+func (o *DictionaryOptions) parseSettings() (gosudachi.Settings, gosudachi.PluginMaker, error) {
+	resourcesdir := o.ResourcesDir
+	if resourcesdir == "" {
+		ex, err := os.Executable()
+		if err != nil {
+			return nil, nil, err
+		}
+		resourcesdir = filepath.Dir(ex)
+	}
+
+	settings, pluginmaker, err := ParseSettings(resourcesdir, o.SettingFile, o.MergeSettings)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to parse settings: %s", err)
+	}
+
+	if o.Utf16String {
+		settings.GetBaseConfig().Utf16String = o.Utf16String
+	}
+	return settings, pluginmaker, nil
+}
+
+// LoadDictionary reads the settings and creates a JapaneseDictionary
+// with the plugins of them.
+func LoadDictionary(o *DictionaryOptions) (*gosudachi.JapaneseDictionary, error) {
+	settings, pluginmaker, err := o.parseSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	inputTextPlugins, err := pluginmaker.GetInputTextPluginArray(gosudachi.DefMakeInputTextPlugin)
+	if err != nil {
+		return nil, fmt.Errorf("fail to cleate any InputTextPlugin: %s", err)
+	}
+	oovProviderPlugins, err := pluginmaker.GetOovProviderPluginArray(gosudachi.DefMakeOovProviderPlugin)
+	if err != nil {
+		return nil, fmt.Errorf("fail to cleate any OovProviderPlugin: %s", err)
+	}
+	pathRewritePlugins, err := pluginmaker.GetPathRewritePluginArray(gosudachi.DefMakePathRewritePlugin)
+	if err != nil {
+		return nil, fmt.Errorf("fail to cleate any PathRewritePlugin: %s", err)
+	}
+	editConnectionCostPlugins, err := pluginmaker.GetEditConnectionCostPluginArray(gosudachi.DefMakeEditConnectionCostPlugin)
+	if err != nil {
+		return nil, fmt.Errorf("fail to cleate any ConnectionCostPlugin: %s", err)
+	}
+
+	return gosudachi.NewJapaneseDictionary(
+		settings.GetBaseConfig(),
+		inputTextPlugins,
+		oovProviderPlugins,
+		pathRewritePlugins,
+		editConnectionCostPlugins,
+	)
+}
+
+// ParseSettings reads the settings from settingfile, or the default
+// settings merged with mergeString if settingfile is empty.
+func ParseSettings(basePath string, settingfile string, mergeString string) (gosudachi.Settings, gosudachi.PluginMaker, error) {
+	settings := gosudachi.NewSettingsJSON()
+
+	var settingsreader io.Reader
+
+	if settingfile != "" {
+		var err error
+		if !filepath.IsAbs(settingfile) {
+			settingfile, err = filepath.Abs(settingfile)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		settingsfd, err := os.OpenFile(settingfile, os.O_RDONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer settingsfd.Close()
+		settingsreader = settingsfd
+	} else {
+		settingsf, err := data.Assets.Open("sudachi.json")
+		if err != nil {
+			return nil, nil, err
+		}
+		defer settingsf.Close()
+		settingsreader = settingsf
+	}
+
+	err := settings.ParseSettingsJSON(basePath, settingsreader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if settingfile == "" && mergeString != "" {
+		err = settings.ParseSettingsJSON(basePath, strings.NewReader(mergeString))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return settings, settings, nil
+}
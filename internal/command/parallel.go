@@ -0,0 +1,91 @@
+package command
+
+import (
+	"sync"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// the number of the lines given to a goroutine at once
+const chunkSize = 64
+
+// TokenizedLine is a line tokenized by TokenizeLines.
+type TokenizedLine struct {
+	Text      string
+	Morphemes *gosudachi.MorphemeList
+	Err       error
+}
+
+type lineChunk struct {
+	lines []TokenizedLine
+	done  chan struct{}
+}
+
+// TokenizeLines tokenizes the lines read by s in parallel with the
+// tokenizers, one goroutine for each, and calls handle with the lines in
+// the order of the input. The error of tokenizing a line is given to
+// handle, which decides whether to go on. TokenizeLines stops at the
+// first error returned by handle or s, and returns after the tokenizers
+// and s are no longer used.
+func TokenizeLines(tokenizers []*gosudachi.JapaneseTokenizer, mode string, s *LineScanner, handle func(line *TokenizedLine) error) error {
+	jobs := make(chan *lineChunk)
+	queue := make(chan *lineChunk, 2*len(tokenizers))
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+
+	wg.Add(len(tokenizers) + 1)
+	for _, tokenizer := range tokenizers {
+		go func(tokenizer *gosudachi.JapaneseTokenizer) {
+			defer wg.Done()
+			for chunk := range jobs {
+				for i := range chunk.lines {
+					line := &chunk.lines[i]
+					line.Morphemes, line.Err = tokenizer.Tokenize(mode, line.Text)
+				}
+				close(chunk.done)
+			}
+		}(tokenizer)
+	}
+
+	go func() {
+		defer wg.Done()
+		defer close(queue)
+		defer close(jobs)
+		for {
+			chunk := &lineChunk{
+				lines: make([]TokenizedLine, 0, chunkSize),
+				done:  make(chan struct{}),
+			}
+			for len(chunk.lines) < chunkSize && s.Scan() {
+				chunk.lines = append(chunk.lines, TokenizedLine{Text: s.Text()})
+			}
+			if len(chunk.lines) == 0 {
+				return
+			}
+			select {
+			case queue <- chunk:
+			case <-quit:
+				return
+			}
+			select {
+			case jobs <- chunk:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	for chunk := range queue {
+		<-chunk.done
+		for i := range chunk.lines {
+			if err := handle(&chunk.lines[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Err()
+}
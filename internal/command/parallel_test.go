@@ -0,0 +1,201 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/dictionary"
+	"github.com/msnoigrs/gosudachi/formatter"
+)
+
+const testLexicon = `東京,0,0,2816,東京,名詞,固有名詞,地名,一般,*,*,トウキョウ,東京,*,A,*,*,*
+都,0,0,2914,都,名詞,普通名詞,一般,*,*,*,ト,都,*,A,*,*,*
+行く,0,0,5105,行く,動詞,非自立可能,*,*,五段-カ行,終止形-一般,イク,行く,*,A,*,*,*
+`
+
+// failingPlugin fails to rewrite the paths of the texts containing "失敗"
+// and counts the paths given to it, taking delay for each.
+type failingPlugin struct {
+	rewrites int64
+	delay    time.Duration
+}
+
+func (p *failingPlugin) GetConfigStruct() interface{} {
+	return nil
+}
+
+func (p *failingPlugin) SetUp(grammar *dictionary.Grammar) error {
+	return nil
+}
+
+func (p *failingPlugin) Rewrite(text *gosudachi.InputText, path *[]*gosudachi.LatticeNode, lattice *gosudachi.Lattice) error {
+	atomic.AddInt64(&p.rewrites, 1)
+	time.Sleep(p.delay)
+	if strings.Contains(text.GetText(), "失敗") {
+		return fmt.Errorf("failed: %s", text.GetText())
+	}
+	return nil
+}
+
+func newTestDictionary(t *testing.T, plugin gosudachi.PathRewritePlugin) *gosudachi.JapaneseDictionary {
+	f, err := ioutil.TempFile("", "command")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	hb, err := dictionary.NewDictionaryHeader(dictionary.SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dicbuilder := dictionary.NewDictionaryBuilder(int64(len(hb)), nil, false)
+	store := dictionary.NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(testLexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader("1 1\n0 0 0\n"), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	var id, cost int16 = 0, 10000
+	dict, err := gosudachi.NewJapaneseDictionary(
+		&gosudachi.BaseConfig{SystemDict: f.Name(), Storage: "heap"},
+		[]gosudachi.InputTextPlugin{},
+		[]gosudachi.OovProviderPlugin{
+			gosudachi.NewSimpleOovProviderPlugin(&gosudachi.SimpleOovProviderPluginConfig{
+				OovPos:  &oovPos,
+				LeftId:  &id,
+				RightId: &id,
+				Cost:    &cost,
+			}),
+		},
+		[]gosudachi.PathRewritePlugin{plugin},
+		[]gosudachi.EditConnectionCostPlugin{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return dict
+}
+
+// testLines returns n lines, every 50th of which cannot be tokenized.
+func testLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		if i%50 == 7 {
+			lines[i] = fmt.Sprintf("失敗%d", i)
+		} else {
+			lines[i] = fmt.Sprintf("東京都%dに行く", i)
+		}
+	}
+	return lines
+}
+
+func TestTokenizeLines(t *testing.T) {
+	plugin := &failingPlugin{}
+	dict := newTestDictionary(t, plugin)
+	defer dict.Close()
+	tokenizers := make([]*gosudachi.JapaneseTokenizer, 4)
+	for i := range tokenizers {
+		tokenizers[i] = dict.Create()
+	}
+
+	lines := testLines(5*chunkSize + 3)
+	input := strings.Join(lines, "\n")
+
+	got := []string{}
+	err := TokenizeLines(tokenizers, "C", NewLineScanner(strings.NewReader(input)), func(line *TokenizedLine) error {
+		failed := strings.HasPrefix(line.Text, "失敗")
+		if failed != (line.Err != nil) {
+			t.Errorf("%s: unexpected error: %v", line.Text, line.Err)
+		}
+		if line.Err == nil && line.Morphemes.GetSurface(0) != "東京" {
+			t.Errorf("%s: invalid morphemes", line.Text)
+		}
+		got = append(got, line.Text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(got, lines) {
+		t.Error("the lines are not in the order of the input")
+	}
+
+	// -f goes on past the lines which cannot be tokenized
+	f, err := formatter.New("wakati", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var output bytes.Buffer
+	err = tokenizeReaderParallel(tokenizers, "C", strings.NewReader(input), &output, f, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "失敗") {
+			want = append(want, line)
+		}
+	}
+	got = strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("want = %d lines, got = %d lines", len(want), len(got))
+	}
+	for i := range want {
+		if strings.Replace(got[i], " ", "", -1) != want[i] {
+			t.Errorf("line %d: want = %s, got = %s", i, want[i], got[i])
+		}
+	}
+
+	// without -f the first error stops
+	err = tokenizeReaderParallel(tokenizers, "C", strings.NewReader(input), &output, f, false)
+	if err == nil || err.Error() != "failed: 失敗7" {
+		t.Errorf("want = failed: 失敗7, got = %v", err)
+	}
+}
+
+func TestTokenizeLinesStop(t *testing.T) {
+	plugin := &failingPlugin{delay: 100 * time.Microsecond}
+	dict := newTestDictionary(t, plugin)
+	defer dict.Close()
+	tokenizers := make([]*gosudachi.JapaneseTokenizer, 4)
+	for i := range tokenizers {
+		tokenizers[i] = dict.Create()
+	}
+
+	input := strings.Join(testLines(100*chunkSize), "\n")
+	stop := errors.New("stop")
+	err := TokenizeLines(tokenizers, "C", NewLineScanner(strings.NewReader(input)), func(line *TokenizedLine) error {
+		return stop
+	})
+	if err != stop {
+		t.Fatalf("want = %s, got = %v", stop, err)
+	}
+	rewrites := atomic.LoadInt64(&plugin.rewrites)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt64(&plugin.rewrites); got != rewrites {
+		t.Errorf("the tokenizers are used after TokenizeLines returns: %d lines, then %d lines", rewrites, got)
+	}
+}
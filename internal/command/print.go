@@ -0,0 +1,63 @@
+package command
+
+import (
+	"flag"
+	"os"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+// Print is the command printing the entries of a dictionary in the
+// lexicon CSV format.
+var Print = &Command{
+	Name:    "print",
+	Usage:   "[-s file] [-j] file",
+	Summary: "print the entries of a dictionary in the lexicon CSV format",
+	Run:     runPrint,
+}
+
+// Header is the command printing the header of a dictionary.
+var Header = &Command{
+	Name:    "header",
+	Usage:   "file",
+	Summary: "print the header and the metadata of a dictionary",
+	Run:     runHeader,
+}
+
+func runPrint(fs *flag.FlagSet, args []string) error {
+	var (
+		systemdict  string
+		utf16string bool
+	)
+	fs.StringVar(&systemdict, "s", "", "system dictionary")
+	setUTF16Flag(fs, &utf16string, "use UTF-16 string")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var sdic *dictionary.BinaryDictionary
+	if systemdict != "" {
+		var err error
+		sdic, err = dictionary.ReadSystemDictionary(systemdict, utf16string)
+		if err != nil {
+			return err
+		}
+		defer sdic.Close()
+	}
+
+	return dictionary.PrintDictionary(fs.Arg(0), utf16string, sdic, os.Stdout)
+}
+
+func runHeader(fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	return dictionary.PrintHeader(fs.Arg(0), os.Stdout)
+}
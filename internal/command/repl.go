@@ -0,0 +1,204 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// Repl is the command tokenizing the lines typed interactively.
+var Repl = &Command{
+	Name:    "repl",
+	Usage:   "[-r file|-s jsonstring] [-p dir] [-j] [-m A|B|C]",
+	Summary: "tokenize the lines typed interactively",
+	Run:     runRepl,
+}
+
+const replHelp = `Type a line to tokenize it, or a command:
+	:mode A|B|C      change the mode of splitting
+	:lattice         show the lattice of the last line
+	:explain         show the rewriting of the input and the path of the last line
+	:lookup text     show the words whose headword is text
+	:split n         show the A and B unit splits of the n-th token of the last line
+	:help            show this message
+	:quit            quit
+`
+
+type repl struct {
+	dict      *gosudachi.JapaneseDictionary
+	tokenizer *gosudachi.JapaneseTokenizer
+	mode      string
+	output    io.Writer
+	lastText  string
+	last      *gosudachi.MorphemeList
+}
+
+func runRepl(fs *flag.FlagSet, args []string) error {
+	var (
+		dictoptions DictionaryOptions
+		mode        string
+	)
+	dictoptions.SetFlags(fs)
+	fs.StringVar(&mode, "m", "C", "mode of splitting")
+	fs.Parse(args)
+
+	if !gosudachi.ValidMode(mode) {
+		return fmt.Errorf("invalid mode: %s", mode)
+	}
+
+	dict, err := LoadDictionary(&dictoptions)
+	if err != nil {
+		return err
+	}
+	defer dict.Close()
+
+	r := &repl{
+		dict:      dict,
+		tokenizer: dict.Create(),
+		mode:      mode,
+		output:    os.Stdout,
+	}
+	fmt.Fprint(r.output, replHelp)
+	return r.loop(os.Stdin)
+}
+
+func (r *repl) loop(input io.Reader) error {
+	s := NewLineScanner(input)
+	for {
+		fmt.Fprintf(r.output, "%s> ", r.mode)
+		if !s.Scan() {
+			fmt.Fprintln(r.output)
+			return s.Err()
+		}
+		line := s.Text()
+		if !strings.HasPrefix(line, ":") {
+			if err := r.tokenize(line); err != nil {
+				fmt.Fprintln(r.output, err)
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		arg := strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+		var err error
+		switch fields[0] {
+		case ":mode":
+			if !gosudachi.ValidMode(arg) {
+				err = fmt.Errorf("invalid mode: %s", arg)
+				break
+			}
+			r.mode = arg
+			if r.last != nil {
+				err = r.tokenize(r.lastText)
+			}
+		case ":lattice":
+			if err = r.needLast(); err == nil {
+				err = r.tokenizer.DumpLattice(r.lastText, r.output)
+			}
+		case ":explain":
+			if err = r.needLast(); err == nil {
+				r.tokenizer.DumpOutput = r.output
+				_, err = r.tokenizer.Tokenize(r.mode, r.lastText)
+				r.tokenizer.DumpOutput = nil
+			}
+		case ":lookup":
+			err = r.lookup(arg)
+		case ":split":
+			err = r.split(arg)
+		case ":help":
+			fmt.Fprint(r.output, replHelp)
+		case ":quit", ":q":
+			return nil
+		default:
+			err = fmt.Errorf("unknown command: %s (:help shows the commands)", fields[0])
+		}
+		if err != nil {
+			fmt.Fprintln(r.output, err)
+		}
+	}
+}
+
+func (r *repl) needLast() error {
+	if r.last == nil {
+		return fmt.Errorf("no line is tokenized yet")
+	}
+	return nil
+}
+
+func (r *repl) printMorphemes(ms *gosudachi.MorphemeList, indent string) {
+	for i := 0; i < ms.Length(); i++ {
+		m := ms.Get(i)
+		fmt.Fprintf(r.output, "%s%d\t%s\t%s\t%s\t%s\t%s",
+			indent,
+			i+1,
+			m.Surface(),
+			strings.Join(m.PartOfSpeech(), ","),
+			m.NormalizedForm(),
+			m.DictionaryForm(),
+			m.ReadingForm())
+		if m.IsOOV() {
+			fmt.Fprint(r.output, "\t(OOV)")
+		}
+		fmt.Fprintln(r.output)
+	}
+}
+
+func (r *repl) tokenize(text string) error {
+	ms, err := r.tokenizer.Tokenize(r.mode, text)
+	if err != nil {
+		return err
+	}
+	r.lastText = text
+	r.last = ms
+	r.printMorphemes(ms, "")
+	return nil
+}
+
+func (r *repl) lookup(text string) error {
+	if text == "" {
+		return fmt.Errorf("usage: :lookup text")
+	}
+	words, err := r.dict.Lookup(text)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		fmt.Fprintf(r.output, "%s is not found\n", text)
+		return nil
+	}
+	for _, word := range words {
+		wi := word.WordInfo
+		fmt.Fprintf(r.output, "%s\t%s\t%s\t%s\t%s\tdict=%d word=%d left=%d right=%d cost=%d\n",
+			wi.Surface,
+			strings.Join(r.dict.GetPartOfSpeechString(wi.PosId), ","),
+			wi.NormalizedForm,
+			wi.DictionaryForm,
+			wi.ReadingForm,
+			word.DictionaryId,
+			word.WordId,
+			word.LeftId,
+			word.RightId,
+			word.Cost)
+	}
+	return nil
+}
+
+func (r *repl) split(arg string) error {
+	if err := r.needLast(); err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > r.last.Length() {
+		return fmt.Errorf("usage: :split n (1 <= n <= %d)", r.last.Length())
+	}
+	m := r.last.Get(n - 1)
+	for _, mode := range []string{"A", "B"} {
+		fmt.Fprintf(r.output, "%s:\n", mode)
+		r.printMorphemes(m.Split(mode), "  ")
+	}
+	return nil
+}
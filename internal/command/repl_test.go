@@ -0,0 +1,44 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReplCommands(t *testing.T) {
+	var output bytes.Buffer
+	r := &repl{
+		mode:   "C",
+		output: &output,
+	}
+	input := strings.Join([]string{
+		":mode X",
+		":mode A",
+		":lattice",
+		":split 1",
+		":unknown",
+		":quit",
+		":mode B",
+	}, "\n")
+	if err := r.loop(strings.NewReader(input)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.mode != "A" {
+		t.Errorf("invalid mode: want = A, got = %s", r.mode)
+	}
+	got := output.String()
+	for _, want := range []string{
+		"C> invalid mode: X\n",
+		"C> A> no line is tokenized yet\n",
+		"A> no line is tokenized yet\n",
+		"A> unknown command: :unknown",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q is not found in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "B>") {
+		t.Errorf("the lines after :quit are read: %q", got)
+	}
+}
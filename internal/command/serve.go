@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/msnoigrs/gosudachi/server"
+)
+
+// Serve is the command serving the HTTP/JSON API of the server package.
+var Serve = &Command{
+	Name:    "serve",
+	Usage:   "[-r file|-s jsonstring] [-p dir] [-j] [-addr host:port]",
+	Summary: "serve the HTTP/JSON API",
+	Run:     runServe,
+}
+
+func runServe(fs *flag.FlagSet, args []string) error {
+	var (
+		dictoptions     DictionaryOptions
+		addr            string
+		maxBodySize     int64
+		maxBatchSize    int
+		shutdownTimeout time.Duration
+	)
+	dictoptions.SetFlags(fs)
+	fs.StringVar(&addr, "addr", "localhost:8080", "address to listen on")
+	fs.Int64Var(&maxBodySize, "max-body", server.DefaultMaxBodySize, "maximum size of a request body in bytes")
+	fs.IntVar(&maxBatchSize, "max-batch", 0, "maximum number of texts of a batch request (0 means no limit)")
+	fs.DurationVar(&shutdownTimeout, "shutdown-timeout", 10*time.Second, "time to wait for the active requests on shutdown")
+	fs.Parse(args)
+
+	dict, err := LoadDictionary(&dictoptions)
+	if err != nil {
+		return err
+	}
+	defer dict.Close()
+
+	handler := server.New(dict)
+	handler.MaxBodySize = maxBodySize
+	handler.MaxBatchSize = maxBatchSize
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		done <- srv.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "listening on %s\n", addr)
+	err = srv.ListenAndServe()
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return <-done
+}
@@ -0,0 +1,178 @@
+package command
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/stats"
+)
+
+// Stats is the command counting the frequencies of the morphemes of a
+// corpus.
+var Stats = &Command{
+	Name:    "stats",
+	Usage:   "[-r file|-s jsonstring] [-p dir] [-j] [-m A|B|C] [-by keys] [-depth n] [-include pos ...] [-exclude pos ...] [-k n] [-format tsv|json] [-P n] [file ...]",
+	Summary: "count the frequencies of the morphemes of a corpus",
+	Run:     runStats,
+}
+
+// StatsResult is the report of the stats command in JSON.
+type StatsResult struct {
+	Mode      string         `json:"mode"`
+	Keys      []string       `json:"keys"`
+	Sentences int64          `json:"sentences"`
+	Tokens    int64          `json:"tokens"`
+	OOV       int64          `json:"oov"`
+	OOVRate   float64        `json:"oovRate"`
+	Counted   int64          `json:"counted"`
+	Types     int            `json:"types"`
+	Entries   []*stats.Entry `json:"entries"`
+}
+
+// stringsFlag is a repeatable flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func runStats(fs *flag.FlagSet, args []string) error {
+	var (
+		dictoptions DictionaryOptions
+		mode        string
+		keys        string
+		posDepth    int
+		include     stringsFlag
+		exclude     stringsFlag
+		top         int
+		format      string
+		parallelism int
+		ignoreerr   bool
+	)
+	dictoptions.SetFlags(fs)
+	fs.StringVar(&mode, "m", "C", "mode of splitting")
+	fs.StringVar(&keys, "by", "dictionary,pos", "comma separated keys to group by ("+strings.Join(stats.KeyNames, "|")+")")
+	fs.IntVar(&posDepth, "depth", 1, "number of the fields of the part of speech in the pos key (0 means all)")
+	fs.Var(&include, "include", "count only the part of speech starting with the comma separated fields (repeatable)")
+	fs.Var(&exclude, "exclude", "skip the part of speech starting with the comma separated fields (repeatable)")
+	fs.IntVar(&top, "k", 100, "number of the most frequent entries to print (0 means all)")
+	fs.StringVar(&format, "format", "tsv", "output format (tsv|json)")
+	fs.IntVar(&parallelism, "P", 1, "number of goroutines tokenizing the lines")
+	fs.BoolVar(&ignoreerr, "f", false, "ignore error")
+	fs.Parse(args)
+
+	if !gosudachi.ValidMode(mode) {
+		return fmt.Errorf("invalid mode: %s", mode)
+	}
+	if format != "tsv" && format != "json" {
+		return fmt.Errorf("unknown format: %s", format)
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	counter, err := stats.NewCounter(strings.Split(keys, ","), posDepth)
+	if err != nil {
+		return err
+	}
+	for _, prefix := range include {
+		counter.Include(prefix)
+	}
+	for _, prefix := range exclude {
+		counter.Exclude(prefix)
+	}
+
+	dict, err := LoadDictionary(&dictoptions)
+	if err != nil {
+		return err
+	}
+	defer dict.Close()
+
+	tokenizers := make([]*gosudachi.JapaneseTokenizer, parallelism)
+	for i := range tokenizers {
+		tokenizers[i] = dict.Create()
+	}
+	countFrom := func(input io.Reader) error {
+		return TokenizeLines(tokenizers, mode, NewLineScanner(input), func(line *TokenizedLine) error {
+			if line.Err != nil {
+				if ignoreerr {
+					fmt.Fprintln(os.Stderr, line.Err)
+					return nil
+				}
+				return line.Err
+			}
+			counter.Add(line.Morphemes)
+			return nil
+		})
+	}
+
+	if fs.NArg() > 0 {
+		for _, arg := range fs.Args() {
+			input, err := os.Open(arg)
+			if err != nil {
+				return err
+			}
+			err = countFrom(input)
+			input.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %s", arg, err)
+			}
+		}
+	} else {
+		err = countFrom(os.Stdin)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries := counter.Top(top)
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(&StatsResult{
+			Mode:      mode,
+			Keys:      counter.Keys(),
+			Sentences: counter.Sentences,
+			Tokens:    counter.Tokens,
+			OOV:       counter.OOV,
+			OOVRate:   counter.OOVRate(),
+			Counted:   counter.Counted,
+			Types:     counter.Types(),
+			Entries:   entries,
+		})
+	}
+
+	// the summary goes to the standard error to keep the output a table
+	fmt.Fprintf(os.Stderr, "sentences: %d, tokens: %d, oov: %d (%.2f%%), counted: %d, types: %d\n",
+		counter.Sentences, counter.Tokens, counter.OOV, 100*counter.OOVRate(), counter.Counted, counter.Types())
+	output := bufio.NewWriter(os.Stdout)
+	writeStatsTSV(output, counter.Keys(), entries)
+	return output.Flush()
+}
+
+// tsvEscaper escapes the characters which cannot be in a field of TSV.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// writeStatsTSV writes the table of the entries with the header of the
+// key names.
+func writeStatsTSV(w io.Writer, keys []string, entries []*stats.Entry) {
+	fmt.Fprintf(w, "%s\tcount\n", strings.Join(keys, "\t"))
+	fields := make([]string, len(keys))
+	for _, e := range entries {
+		for i, k := range e.Key {
+			fields[i] = tsvEscaper.Replace(k)
+		}
+		fmt.Fprintf(w, "%s\t%d\n", strings.Join(fields, "\t"), e.Count)
+	}
+}
@@ -0,0 +1,24 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi/stats"
+)
+
+func TestWriteStatsTSV(t *testing.T) {
+	var output bytes.Buffer
+	writeStatsTSV(&output, []string{"surface", "pos"}, []*stats.Entry{
+		{Key: []string{"東京", "名詞"}, Count: 3},
+		{Key: []string{"a\tb", "補助記号"}, Count: 2},
+		{Key: []string{"\\n\n", "空白"}, Count: 1},
+	})
+	want := "surface\tpos\tcount\n" +
+		"東京\t名詞\t3\n" +
+		"a\\tb\t補助記号\t2\n" +
+		"\\\\n\\n\t空白\t1\n"
+	if got := output.String(); got != want {
+		t.Errorf("invalid output: want = %q, got = %q", want, got)
+	}
+}
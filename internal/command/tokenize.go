@@ -0,0 +1,186 @@
+package command
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/formatter"
+)
+
+// Tokenize is the command tokenizing the lines of the files or the
+// standard input.
+var Tokenize = &Command{
+	Name:    "tokenize",
+	Usage:   "[-r file|-s jsonstring] [-m A|B|C] [-format name] [-o file] [-p dir] [-j] [-P n] [file ...]",
+	Summary: "tokenize the lines of the files or the standard input",
+	Run:     runTokenize,
+}
+
+func tokenizeReader(tokenizer *gosudachi.JapaneseTokenizer, mode string, input io.Reader, output io.Writer, f formatter.Formatter, ignoreError bool) error {
+	s := NewLineScanner(input)
+	for s.Scan() {
+		err := tokenizeLine(tokenizer, mode, s.Text(), output, f)
+		if err != nil {
+			if ignoreError {
+				fmt.Fprintln(os.Stderr, err)
+			} else {
+				return err
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func tokenizeReaderParallel(tokenizers []*gosudachi.JapaneseTokenizer, mode string, input io.Reader, output io.Writer, f formatter.Formatter, ignoreError bool) error {
+	return TokenizeLines(tokenizers, mode, NewLineScanner(input), func(line *TokenizedLine) error {
+		err := line.Err
+		if err == nil {
+			err = f.Format(output, line.Text, line.Morphemes)
+		}
+		if err != nil {
+			if ignoreError {
+				fmt.Fprintln(os.Stderr, err)
+			} else {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func tokenizeLine(tokenizer *gosudachi.JapaneseTokenizer, mode string, text string, output io.Writer, f formatter.Formatter) error {
+	ms, err := tokenizer.Tokenize(mode, text)
+	if err != nil {
+		return err
+	}
+	return f.Format(output, text, ms)
+}
+
+func runTokenize(fs *flag.FlagSet, args []string) error {
+	var (
+		dictoptions DictionaryOptions
+		mode        string
+		outputfile  string
+		format      string
+		features    string
+		uposfile    string
+		printall    bool
+		ignoreerr   bool
+		debugmode   bool
+		parallelism int
+	)
+	dictoptions.SetFlags(fs)
+	fs.StringVar(&mode, "m", "C", "mode of splitting")
+	fs.StringVar(&outputfile, "o", "", "output to file")
+	fs.StringVar(&format, "format", "sudachi", "output format ("+strings.Join(formatter.Names, "|")+")")
+	fs.StringVar(&features, "features", "", "comma separated feature columns of the mecab format")
+	fs.StringVar(&uposfile, "upos", "", "UPOS mapping table of the conllu format")
+	fs.BoolVar(&printall, "a", false, "print all fields (with the A and B unit splits in json formats)")
+	fs.BoolVar(&ignoreerr, "f", false, "ignore error")
+	fs.BoolVar(&debugmode, "d", false, "debug mode")
+	fs.IntVar(&parallelism, "P", 1, "number of goroutines tokenizing the lines")
+	fs.Parse(args)
+
+	if !gosudachi.ValidMode(mode) {
+		return fmt.Errorf("invalid mode: %s", mode)
+	}
+
+	options := &formatter.Options{
+		PrintAll: printall,
+		Splits:   printall,
+	}
+	if features != "" {
+		options.MeCabFeatures = strings.Split(features, ",")
+	}
+	if uposfile != "" {
+		uposfd, err := os.Open(uposfile)
+		if err != nil {
+			return err
+		}
+		options.UPOSTable, err = formatter.ReadUPOSTable(uposfd)
+		uposfd.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s", uposfile, err)
+		}
+	}
+	if parallelism > 1 && debugmode {
+		return fmt.Errorf("-d cannot be used with -P")
+	}
+
+	f, err := formatter.New(format, options)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer
+	if outputfile != "" {
+		if !filepath.IsAbs(outputfile) {
+			outputfile, err = filepath.Abs(outputfile)
+			if err != nil {
+				return err
+			}
+		}
+		outputfd, err := os.OpenFile(outputfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("%s: %s", outputfile, err)
+		}
+		defer outputfd.Close()
+		bufiooutput := bufio.NewWriter(outputfd)
+		defer bufiooutput.Flush()
+		output = bufiooutput
+	} else {
+		output = os.Stdout
+	}
+
+	dict, err := LoadDictionary(&dictoptions)
+	if err != nil {
+		return err
+	}
+	defer dict.Close()
+
+	tokenizer := dict.Create()
+	if debugmode {
+		tokenizer.DumpOutput = output
+	}
+	tokenizeFrom := func(input io.Reader) error {
+		return tokenizeReader(tokenizer, mode, input, output, f, ignoreerr)
+	}
+	if parallelism > 1 {
+		tokenizers := make([]*gosudachi.JapaneseTokenizer, parallelism)
+		for i := range tokenizers {
+			tokenizers[i] = dict.Create()
+		}
+		tokenizeFrom = func(input io.Reader) error {
+			return tokenizeReaderParallel(tokenizers, mode, input, output, f, ignoreerr)
+		}
+	}
+
+	if fs.NArg() > 0 {
+		for _, arg := range fs.Args() {
+			input, err := os.OpenFile(arg, os.O_RDONLY, 0644)
+			if err != nil {
+				return fmt.Errorf("%s: %s", arg, err)
+			}
+			err = tokenizeFrom(input)
+			input.Close()
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		err = tokenizeFrom(os.Stdin)
+		if err != nil {
+			return err
+		}
+	}
+	return formatter.Finish(f, output)
+}
@@ -0,0 +1,23 @@
+package mmap
+
+// Advice is the expected access pattern of a mapped region.
+type Advice int
+
+const (
+	AdviceNormal Advice = iota
+	AdviceRandom
+	AdviceSequential
+	AdviceWillNeed
+)
+
+const pageSize = 4096
+
+// Populate touches every page of b so that the following accesses do not
+// cause page faults.
+func Populate(b []byte) byte {
+	var sum byte
+	for i := 0; i < len(b); i += pageSize {
+		sum += b[i]
+	}
+	return sum
+}
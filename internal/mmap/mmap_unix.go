@@ -22,10 +22,21 @@ func Munmap(b []byte) error {
 	return unix.Munmap(b)
 }
 
-func Madvise(b []byte, readahead bool) error {
-	flags := unix.MADV_NORMAL
-	if !readahead {
+// Madvise tells the kernel the expected access pattern of b.
+func Madvise(b []byte, advice Advice) error {
+	if len(b) == 0 {
+		return nil
+	}
+	var flags int
+	switch advice {
+	case AdviceRandom:
 		flags = unix.MADV_RANDOM
+	case AdviceSequential:
+		flags = unix.MADV_SEQUENTIAL
+	case AdviceWillNeed:
+		flags = unix.MADV_WILLNEED
+	default:
+		flags = unix.MADV_NORMAL
 	}
 	return madvise(b, flags)
 }
@@ -67,7 +67,7 @@ func Munmap(b []byte) error {
 	return syscall.UnmapViewOfFile(uintptr(unsafe.Pointer(&b[0])))
 }
 
-func Madvise(b []byte, readahead bool) error {
+func Madvise(b []byte, advice Advice) error {
 	// Do Nothing. We don't care about this setting on Windows
 	return nil
 }
@@ -0,0 +1,148 @@
+package gosudachi
+
+import (
+	"unicode"
+)
+
+type IterationMarkInputTextPluginConfig struct {
+	Kanji     *bool
+	Hiragana  *bool
+	Katakana  *bool
+	Kunojiten *bool
+}
+
+// pairs of an unvoiced kana and its voiced one
+const voicedKanaPairs = "かがきぎくぐけげこごさざしじすずせぜそぞただちぢつづてでとどはばひびふぶへべほぼ" +
+	"カガキギクグケゲコゴサザシジスズセゼソゾタダチヂツヅテデトドハバヒビフブヘベホボウヴ"
+
+var (
+	voicedKana   = map[rune]rune{}
+	unvoicedKana = map[rune]rune{}
+)
+
+func init() {
+	pairs := []rune(voicedKanaPairs)
+	for i := 0; i < len(pairs); i += 2 {
+		voicedKana[pairs[i]] = pairs[i+1]
+		unvoicedKana[pairs[i+1]] = pairs[i]
+	}
+}
+
+func toVoiced(r rune) rune {
+	if v, ok := voicedKana[r]; ok {
+		return v
+	}
+	return r
+}
+
+func toUnvoiced(r rune) rune {
+	if v, ok := unvoicedKana[r]; ok {
+		return v
+	}
+	return r
+}
+
+// IterationMarkInputTextPlugin expands the iteration marks into the
+// characters they repeat: 々 (kanji), ゝ and ゞ (hiragana), ヽ and ヾ
+// (katakana), and 〱, 〲, 〳〵 and 〴〵 (kunojiten) which repeat the
+// two preceding characters.
+type IterationMarkInputTextPlugin struct {
+	config    *IterationMarkInputTextPluginConfig
+	kanji     bool
+	hiragana  bool
+	katakana  bool
+	kunojiten bool
+}
+
+func NewIterationMarkInputTextPlugin(config *IterationMarkInputTextPluginConfig) *IterationMarkInputTextPlugin {
+	if config == nil {
+		config = &IterationMarkInputTextPluginConfig{}
+	}
+	return &IterationMarkInputTextPlugin{
+		config: config,
+	}
+}
+
+func (p *IterationMarkInputTextPlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &IterationMarkInputTextPluginConfig{}
+	}
+	return p.config
+}
+
+func (p *IterationMarkInputTextPlugin) SetUp() error {
+	enabled := func(b *bool) bool {
+		return b == nil || *b
+	}
+	p.kanji = enabled(p.config.Kanji)
+	p.hiragana = enabled(p.config.Hiragana)
+	p.katakana = enabled(p.config.Katakana)
+	p.kunojiten = enabled(p.config.Kunojiten)
+	p.config = nil
+	return nil
+}
+
+// expand returns the replacement of the mark at runes[i] and the length
+// of the mark, or nil. text is the expanded text before the mark.
+func (p *IterationMarkInputTextPlugin) expand(runes []rune, i int, text []rune) ([]rune, int) {
+	if len(text) == 0 {
+		return nil, 0
+	}
+	prev := text[len(text)-1]
+	switch runes[i] {
+	case '々':
+		if p.kanji && unicode.Is(unicode.Han, prev) && prev != '々' {
+			return []rune{prev}, 1
+		}
+	case 'ゝ', 'ゞ':
+		if p.hiragana && unicode.Is(unicode.Hiragana, prev) {
+			if runes[i] == 'ゞ' {
+				return []rune{toVoiced(prev)}, 1
+			}
+			return []rune{toUnvoiced(prev)}, 1
+		}
+	case 'ヽ', 'ヾ':
+		if p.katakana && unicode.Is(unicode.Katakana, prev) && prev != 'ー' {
+			if runes[i] == 'ヾ' {
+				return []rune{toVoiced(prev)}, 1
+			}
+			return []rune{toUnvoiced(prev)}, 1
+		}
+	case '〱', '〲', '〳', '〴':
+		if !p.kunojiten || len(text) < 2 {
+			break
+		}
+		length := 1
+		if runes[i] == '〳' || runes[i] == '〴' {
+			if i+1 >= len(runes) || runes[i+1] != '〵' {
+				break
+			}
+			length = 2
+		}
+		first := toUnvoiced(text[len(text)-2])
+		if runes[i] == '〲' || runes[i] == '〴' {
+			first = toVoiced(first)
+		}
+		return []rune{first, prev}, length
+	}
+	return nil, 0
+}
+
+func (p *IterationMarkInputTextPlugin) Rewrite(builder *InputTextBuilder) error {
+	runes := builder.GetText()
+	text := make([]rune, 0, len(runes))
+	offset := 0
+	for i := 0; i < len(runes); {
+		replacement, length := p.expand(runes, i, text)
+		if replacement == nil {
+			text = append(text, runes[i])
+			i++
+			continue
+		}
+		builder.Replace(i+offset, i+offset+length, replacement)
+		offset += len(replacement) - length
+		text = append(text, replacement...)
+		i += length
+	}
+	return nil
+}
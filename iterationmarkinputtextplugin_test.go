@@ -0,0 +1,44 @@
+package gosudachi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIterationMarkInputTextPlugin(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		config  *IterationMarkInputTextPluginConfig
+		text    string
+		want    string
+		offsets []int
+	}{
+		{nil, "人々", "人人", []int{0, 1, 2}},
+		{nil, "いすゞ", "いすず", []int{0, 1, 2, 3}},
+		{nil, "こゝろ", "こころ", []int{0, 1, 2, 3}},
+		{nil, "ぶゝ", "ぶふ", []int{0, 1, 2}},
+		{nil, "バヽ", "バハ", []int{0, 1, 2}},
+		{nil, "いよ〱", "いよいよ", []int{0, 1, 2, 2, 3}},
+		{nil, "しみ〴〵", "しみじみ", []int{0, 1, 2, 2, 4}},
+		{nil, "々ゝ", "々ゝ", []int{0, 1, 2}},
+		{&IterationMarkInputTextPluginConfig{Kanji: &disabled}, "人々こゝ", "人々ここ", []int{0, 1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		plugin := NewIterationMarkInputTextPlugin(tt.config)
+		err := plugin.SetUp()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		builder := NewInputTextBuilder(tt.text, nil)
+		err = plugin.Rewrite(builder)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := string(builder.GetText()); got != tt.want {
+			t.Errorf("%s: want = %s, got = %s", tt.text, tt.want, got)
+		}
+		if !reflect.DeepEqual(builder.textOffsets, tt.offsets) {
+			t.Errorf("%s: want = %v, got = %v", tt.text, tt.offsets, builder.textOffsets)
+		}
+	}
+}
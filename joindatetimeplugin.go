@@ -0,0 +1,254 @@
+package gosudachi
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+type JoinDateTimePluginConfig struct {
+	POS *[]string
+}
+
+type era struct {
+	name      []rune
+	firstYear int
+}
+
+var eras = []era{
+	{[]rune("明治"), 1868},
+	{[]rune("大正"), 1912},
+	{[]rune("昭和"), 1926},
+	{[]rune("平成"), 1989},
+	{[]rune("令和"), 2019},
+}
+
+// JoinDateTimePlugin concatenates the nodes of a Japanese date and time
+// expression into a node whose normalized form is in ISO 8601, such as
+// 2021-04-01, 2021-04, --04-01, 15:30 or 2021-04-01T15:30.
+type JoinDateTimePlugin struct {
+	config *JoinDateTimePluginConfig
+	posId  int16
+}
+
+func NewJoinDateTimePlugin(config *JoinDateTimePluginConfig) *JoinDateTimePlugin {
+	if config == nil {
+		config = &JoinDateTimePluginConfig{}
+	}
+	return &JoinDateTimePlugin{
+		config: config,
+	}
+}
+
+func (p *JoinDateTimePlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &JoinDateTimePluginConfig{}
+	}
+	return p.config
+}
+
+func (p *JoinDateTimePlugin) SetUp(grammar *dictionary.Grammar) error {
+	p.posId = -1
+	if p.config.POS != nil {
+		if len(*p.config.POS) != dictionary.PosDepth {
+			return fmt.Errorf("JoinDateTimePlugin: pos is invalid")
+		}
+		p.posId = grammar.GetPartOfSpeechId(*p.config.POS)
+		if p.posId < 0 {
+			return fmt.Errorf("JoinDateTimePlugin: pos is invalid")
+		}
+	}
+	p.config = nil
+	return nil
+}
+
+// dateTimeCandidate is an expression that ends at the rune index end.
+type dateTimeCandidate struct {
+	end        int
+	normalized string
+}
+
+type dateTimeScanner struct {
+	runes  []rune
+	parser *numericParser
+}
+
+func (sc *dateTimeScanner) hasPrefix(pos int, s []rune) bool {
+	if pos+len(s) > len(sc.runes) {
+		return false
+	}
+	for i, r := range s {
+		if sc.runes[pos+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// number reads the integer at pos followed by the unit.
+func (sc *dateTimeScanner) number(pos int, unit rune) (int, int, bool) {
+	sc.parser.clear()
+	end := pos
+	for end < len(sc.runes) {
+		if _, ok := runeToNumMap[sc.runes[end]]; !ok {
+			break
+		}
+		if !sc.parser.append(sc.runes[end]) {
+			return 0, pos, false
+		}
+		end++
+	}
+	if end == pos || end == len(sc.runes) || sc.runes[end] != unit || !sc.parser.done() {
+		return 0, pos, false
+	}
+	n, err := strconv.Atoi(sc.parser.getNormalized())
+	if err != nil {
+		return 0, pos, false
+	}
+	return n, end + 1, true
+}
+
+// year reads a year with or without an era name. The last result
+// reports whether an era name is given.
+func (sc *dateTimeScanner) year(pos int) (int, int, bool, bool) {
+	for _, e := range eras {
+		if !sc.hasPrefix(pos, e.name) {
+			continue
+		}
+		p := pos + len(e.name)
+		if p+1 < len(sc.runes) && sc.runes[p] == '元' && sc.runes[p+1] == '年' {
+			return e.firstYear, p + 2, true, true
+		}
+		n, p, ok := sc.number(p, '年')
+		if !ok || n < 1 {
+			return 0, pos, false, false
+		}
+		return e.firstYear + n - 1, p, true, true
+	}
+	n, p, ok := sc.number(pos, '年')
+	return n, p, ok, false
+}
+
+func (sc *dateTimeScanner) time(pos int, prefix string, candidates []dateTimeCandidate) []dateTimeCandidate {
+	p := pos
+	pm := false
+	maxHour := 24
+	if sc.hasPrefix(p, []rune("午前")) {
+		p += 2
+		maxHour = 12
+	} else if sc.hasPrefix(p, []rune("午後")) {
+		p += 2
+		maxHour = 12
+		pm = true
+	}
+	hour, p, ok := sc.number(p, '時')
+	if !ok || hour > maxHour {
+		return candidates
+	}
+	if pm && hour < 12 {
+		hour += 12
+	} else if maxHour == 12 && !pm && hour == 12 {
+		hour = 0
+	}
+	// 24:00 is the end of the day, and no time follows it
+	endOfDay := hour == 24
+	if p < len(sc.runes) && sc.runes[p] == '半' {
+		if endOfDay {
+			return candidates
+		}
+		return append(candidates, dateTimeCandidate{p + 1, fmt.Sprintf("%s%02d:30", prefix, hour)})
+	}
+	candidates = append(candidates, dateTimeCandidate{p, fmt.Sprintf("%s%02d:00", prefix, hour)})
+	minute, p, ok := sc.number(p, '分')
+	if !ok || minute > 59 || (endOfDay && minute != 0) {
+		return candidates
+	}
+	candidates = append(candidates, dateTimeCandidate{p, fmt.Sprintf("%s%02d:%02d", prefix, hour, minute)})
+	second, p, ok := sc.number(p, '秒')
+	if !ok || second > 59 || (endOfDay && second != 0) {
+		return candidates
+	}
+	return append(candidates, dateTimeCandidate{p, fmt.Sprintf("%s%02d:%02d:%02d", prefix, hour, minute, second)})
+}
+
+// validDate reports whether the day exists in the month. A date without
+// a year is checked in a leap year.
+func validDate(year int, hasYear bool, month int, day int) bool {
+	if !hasYear {
+		year = 2000
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t.Year() == year && t.Month() == time.Month(month) && t.Day() == day
+}
+
+// scan returns the date and time expressions starting at pos, the
+// shorter first.
+func (sc *dateTimeScanner) scan(pos int) []dateTimeCandidate {
+	candidates := []dateTimeCandidate{}
+	var date string
+	year, p, hasYear, hasEra := sc.year(pos)
+	if hasYear {
+		date = fmt.Sprintf("%04d", year)
+		// a small year without an era name and a month is rather a
+		// number of years
+		if hasEra || year >= 1000 {
+			candidates = append(candidates, dateTimeCandidate{p, date})
+		}
+	}
+	month, p, ok := sc.number(p, '月')
+	if !ok || month < 1 || month > 12 {
+		if hasYear {
+			return candidates
+		}
+		return sc.time(pos, "", candidates)
+	}
+	if hasYear {
+		date = fmt.Sprintf("%s-%02d", date, month)
+		candidates = append(candidates, dateTimeCandidate{p, date})
+	} else {
+		date = fmt.Sprintf("--%02d", month)
+	}
+	day, p, ok := sc.number(p, '日')
+	if !ok || !validDate(year, hasYear, month, day) {
+		return candidates
+	}
+	date = fmt.Sprintf("%s-%02d", date, day)
+	candidates = append(candidates, dateTimeCandidate{p, date})
+	return sc.time(p, date+"T", candidates)
+}
+
+func (p *JoinDateTimePlugin) Rewrite(text *InputText, path *[]*LatticeNode, lattice *Lattice) error {
+	sc := &dateTimeScanner{
+		runes:  []rune(text.GetText()),
+		parser: newNumericParser(),
+	}
+	for i := 0; i < len(*path); i++ {
+		begin := text.GetOffsetTextLength((*path)[i].Begin)
+		candidates := sc.scan(begin)
+		for c := len(candidates) - 1; c >= 0; c-- {
+			candidate := candidates[c]
+			end := i
+			for end < len(*path) && text.GetOffsetTextLength((*path)[end].End) < candidate.end {
+				end++
+			}
+			if end == len(*path) || text.GetOffsetTextLength((*path)[end].End) != candidate.end {
+				continue
+			}
+			end++
+			if end-i == 1 && (*path)[i].GetWordInfo().NormalizedForm == candidate.normalized {
+				break
+			}
+			node, err := ConcatenateNodes(path, i, end, lattice, candidate.normalized)
+			if err != nil {
+				return fmt.Errorf("JoinDateTimePlugin: %s", err)
+			}
+			if p.posId >= 0 {
+				node.GetWordInfo().PosId = p.posId
+			}
+			break
+		}
+	}
+	return nil
+}
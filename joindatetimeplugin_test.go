@@ -0,0 +1,88 @@
+package gosudachi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDateTimeScanner(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"令和3年4月1日に", "2021-04-01"},
+		{"平成元年", "1989"},
+		{"二千二十一年十二月", "2021-12"},
+		{"2021年4月1日午後3時半", "2021-04-01T15:30"},
+		{"4月1日", "--04-01"},
+		{"午前12時5分10秒", "00:05:10"},
+		{"15時", "15:00"},
+		{"3年", ""},
+		{"13月1日", ""},
+		{"午後13時", ""},
+		{"2021年2月31日", "2021-02"},
+		{"2021年2月29日", "2021-02"},
+		{"2020年2月29日", "2020-02-29"},
+		{"2月29日", "--02-29"},
+		{"4月31日", ""},
+		{"24時", "24:00"},
+		{"24時30分", "24:00"},
+		{"24時半", ""},
+		{"24時0分0秒", "24:00:00"},
+	}
+	for _, tt := range tests {
+		sc := &dateTimeScanner{
+			runes:  []rune(tt.text),
+			parser: newNumericParser(),
+		}
+		candidates := sc.scan(0)
+		got := ""
+		if len(candidates) > 0 {
+			got = candidates[len(candidates)-1].normalized
+		}
+		if got != tt.want {
+			t.Errorf("%s: want = %s, got = %s", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestJoinDateTimePluginRewrite(t *testing.T) {
+	pos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	dict := newTestDictionary(t, []PathRewritePlugin{
+		NewJoinDateTimePlugin(&JoinDateTimePluginConfig{POS: &pos}),
+	}, nil)
+	defer dict.Close()
+	tokenizer := dict.Create()
+
+	tests := []struct {
+		text       string
+		surfaces   []string
+		index      int
+		normalized string
+	}{
+		{"2020年2月29日に行く", []string{"2020年2月29日", "に", "行く"}, 0, "2020-02-29"},
+		{"東京に午後3時半", []string{"東京", "に", "午後3時半"}, 2, "15:30"},
+		{"東京に2021年2月31日", []string{"東京", "に", "2021年2月", "3", "1", "日"}, 2, "2021-02"},
+	}
+	for _, tt := range tests {
+		ms, err := tokenizer.Tokenize("C", tt.text)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.text, err)
+		}
+		surfaces := []string{}
+		for i := 0; i < ms.Length(); i++ {
+			surfaces = append(surfaces, ms.GetSurface(i))
+		}
+		if !reflect.DeepEqual(surfaces, tt.surfaces) {
+			t.Errorf("%s: want = %v, got = %v", tt.text, tt.surfaces, surfaces)
+			continue
+		}
+		m := ms.Get(tt.index)
+		if m.NormalizedForm() != tt.normalized {
+			t.Errorf("%s: want = %s, got = %s", tt.text, tt.normalized, m.NormalizedForm())
+		}
+		if got := m.PartOfSpeech(); !reflect.DeepEqual(got, pos) {
+			t.Errorf("%s: invalid pos: %v", tt.text, got)
+		}
+	}
+}
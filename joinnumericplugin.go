@@ -7,13 +7,19 @@ import (
 )
 
 type JoinNumericPluginConfig struct {
-	EnableNormalize *bool
+	EnableNormalize       *bool
+	EnableStructuredValue *bool
+	CurrencyUnits         *[]string
+	QuantityUnits         *[]string
 }
 
 type JoinNumericPlugin struct {
-	config          *JoinNumericPluginConfig
-	enableNormalize bool
-	numericPosId    int16
+	config                *JoinNumericPluginConfig
+	enableNormalize       bool
+	enableStructuredValue bool
+	currencyUnits         []string
+	quantityUnits         []string
+	numericPosId          int16
 }
 
 func NewJoinNumericPlugin(config *JoinNumericPluginConfig) *JoinNumericPlugin {
@@ -39,6 +45,17 @@ func (p *JoinNumericPlugin) SetUp(grammar *dictionary.Grammar) error {
 	} else {
 		p.enableNormalize = *p.config.EnableNormalize
 	}
+	if p.config.EnableStructuredValue != nil {
+		p.enableStructuredValue = *p.config.EnableStructuredValue
+	}
+	p.currencyUnits = defaultCurrencyUnits
+	if p.config.CurrencyUnits != nil {
+		p.currencyUnits = *p.config.CurrencyUnits
+	}
+	p.quantityUnits = defaultQuantityUnits
+	if p.config.QuantityUnits != nil {
+		p.quantityUnits = *p.config.QuantityUnits
+	}
 	p.config = nil
 	return nil
 }
@@ -148,6 +165,16 @@ func (p *JoinNumericPlugin) Rewrite(text *InputText, path *[]*LatticeNode, latti
 			}
 		}
 	}
+
+	if p.enableStructuredValue {
+		for i := 0; i < len(*path); i++ {
+			index, err := p.structure(path, i, lattice, parser)
+			if err != nil {
+				return fmt.Errorf("JoinNumericPlugin: %s", err)
+			}
+			i = index
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,182 @@
+package gosudachi
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+type PosSequenceElementConfig struct {
+	POS     *[]string
+	Surface *string
+	Repeat  *bool
+}
+
+type PosSequenceRuleConfig struct {
+	Pattern        *[]PosSequenceElementConfig
+	ResultPOS      *[]string
+	NormalizedForm *string
+}
+
+type JoinPosSequencePluginConfig struct {
+	Rules *[]PosSequenceRuleConfig
+}
+
+const (
+	normalizedFormConcat  = "concat"
+	normalizedFormSurface = "surface"
+)
+
+type posSequenceElement struct {
+	pos     []string
+	surface *regexp.Regexp
+	repeat  bool
+}
+
+type posSequenceRule struct {
+	pattern          []*posSequenceElement
+	resultPosId      int16
+	surfaceNormalize bool
+}
+
+// JoinPosSequencePlugin concatenates the nodes matching a sequence of
+// part of speech patterns into a node of the result part of speech.
+// The rules are tried in order at each node and the first one that
+// matches two or more nodes is applied.
+type JoinPosSequencePlugin struct {
+	config  *JoinPosSequencePluginConfig
+	grammar *dictionary.Grammar
+	rules   []*posSequenceRule
+}
+
+func NewJoinPosSequencePlugin(config *JoinPosSequencePluginConfig) *JoinPosSequencePlugin {
+	if config == nil {
+		config = &JoinPosSequencePluginConfig{}
+	}
+	return &JoinPosSequencePlugin{
+		config: config,
+	}
+}
+
+func (p *JoinPosSequencePlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &JoinPosSequencePluginConfig{}
+	}
+	return p.config
+}
+
+func (p *JoinPosSequencePlugin) SetUp(grammar *dictionary.Grammar) error {
+	if p.config.Rules == nil {
+		return fmt.Errorf("JoinPosSequencePlugin: rules is not specified")
+	}
+	p.rules = make([]*posSequenceRule, 0, len(*p.config.Rules))
+	for i, rc := range *p.config.Rules {
+		if rc.Pattern == nil || len(*rc.Pattern) == 0 {
+			return fmt.Errorf("JoinPosSequencePlugin: pattern is not specified in rules[%d]", i)
+		}
+		if rc.ResultPOS == nil || len(*rc.ResultPOS) != dictionary.PosDepth {
+			return fmt.Errorf("JoinPosSequencePlugin: resultPOS is not specified in rules[%d]", i)
+		}
+		rule := &posSequenceRule{
+			pattern:     make([]*posSequenceElement, 0, len(*rc.Pattern)),
+			resultPosId: grammar.GetPartOfSpeechId(*rc.ResultPOS),
+		}
+		if rule.resultPosId < 0 {
+			return fmt.Errorf("JoinPosSequencePlugin: resultPOS is invalid in rules[%d]", i)
+		}
+		if rc.NormalizedForm != nil {
+			switch *rc.NormalizedForm {
+			case normalizedFormConcat:
+			case normalizedFormSurface:
+				rule.surfaceNormalize = true
+			default:
+				return fmt.Errorf("JoinPosSequencePlugin: %s is unknown normalizedForm in rules[%d]", *rc.NormalizedForm, i)
+			}
+		}
+		for j, ec := range *rc.Pattern {
+			element := &posSequenceElement{}
+			if ec.POS != nil {
+				if len(*ec.POS) > dictionary.PosDepth {
+					return fmt.Errorf("JoinPosSequencePlugin: pos is too long in rules[%d].pattern[%d]", i, j)
+				}
+				element.pos = *ec.POS
+			}
+			if ec.Surface != nil {
+				re, err := regexp.Compile(`^(?:` + *ec.Surface + `)$`)
+				if err != nil {
+					return fmt.Errorf("JoinPosSequencePlugin: %s in rules[%d].pattern[%d]", err, i, j)
+				}
+				element.surface = re
+			}
+			if ec.Repeat != nil {
+				element.repeat = *ec.Repeat
+			}
+			rule.pattern = append(rule.pattern, element)
+		}
+		p.rules = append(p.rules, rule)
+	}
+	p.grammar = grammar
+	p.config = nil
+	return nil
+}
+
+// matchNode reports whether the node matches the element.
+func (p *JoinPosSequencePlugin) matchNode(element *posSequenceElement, node *LatticeNode) bool {
+	wi := node.GetWordInfo()
+	if len(element.pos) > 0 && !matchPOS(element.pos, p.grammar.GetPartOfSpeechString(wi.PosId)) {
+		return false
+	}
+	if element.surface != nil && !element.surface.MatchString(wi.Surface) {
+		return false
+	}
+	return true
+}
+
+// match returns the end of the nodes matching pattern from begin, or -1.
+// A repeated element matches as many nodes as possible and gives them
+// back one by one if the rest of the pattern does not match.
+func (p *JoinPosSequencePlugin) match(pattern []*posSequenceElement, path []*LatticeNode, begin int) int {
+	if len(pattern) == 0 {
+		return begin
+	}
+	element := pattern[0]
+	if !element.repeat {
+		if begin < len(path) && p.matchNode(element, path[begin]) {
+			return p.match(pattern[1:], path, begin+1)
+		}
+		return -1
+	}
+	end := begin
+	for end < len(path) && p.matchNode(element, path[end]) {
+		end++
+	}
+	for ; end > begin; end-- {
+		if e := p.match(pattern[1:], path, end); e >= 0 {
+			return e
+		}
+	}
+	return -1
+}
+
+func (p *JoinPosSequencePlugin) Rewrite(text *InputText, path *[]*LatticeNode, lattice *Lattice) error {
+	for i := 0; i < len(*path); i++ {
+		for _, rule := range p.rules {
+			end := p.match(rule.pattern, *path, i)
+			if end-i < 2 {
+				continue
+			}
+			normalizedForm := ""
+			if rule.surfaceNormalize {
+				normalizedForm = text.GetSubstring((*path)[i].Begin, (*path)[end-1].End)
+			}
+			node, err := ConcatenateNodes(path, i, end, lattice, normalizedForm)
+			if err != nil {
+				return fmt.Errorf("JoinPosSequencePlugin: %s", err)
+			}
+			node.GetWordInfo().PosId = rule.resultPosId
+			break
+		}
+	}
+	return nil
+}
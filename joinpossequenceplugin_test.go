@@ -0,0 +1,96 @@
+package gosudachi
+
+import (
+	"testing"
+)
+
+func TestJoinPosSequencePluginMatch(t *testing.T) {
+	dict := newTestDictionary(t, nil, nil)
+	defer dict.Close()
+	ms, err := dict.Create().Tokenize("C", "東京都に行く")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	path := ms.path
+
+	yes := true
+	noun := []string{"名詞"}
+	commonNoun := []string{"*", "普通名詞"}
+	particle := []string{"助詞", "格助詞", "*", "*", "*", "*"}
+	verb := []string{"動詞", "*", "*", "*", "*", "終止形-一般"}
+	tokyo, kyo := "東.*", "京"
+	tests := []struct {
+		pattern []PosSequenceElementConfig
+		begin   int
+		want    int
+	}{
+		{[]PosSequenceElementConfig{{POS: &noun}, {POS: &noun}}, 0, 2},
+		{[]PosSequenceElementConfig{{POS: &noun, Repeat: &yes}}, 0, 2},
+		// the repeated element gives back the last noun
+		{[]PosSequenceElementConfig{{POS: &noun, Repeat: &yes}, {POS: &commonNoun}}, 0, 2},
+		{[]PosSequenceElementConfig{{POS: &noun, Repeat: &yes}, {POS: &particle}, {POS: &verb}}, 0, 4},
+		{[]PosSequenceElementConfig{{POS: &commonNoun}, {POS: &particle}}, 1, 3},
+		{[]PosSequenceElementConfig{{POS: &commonNoun}}, 0, -1},
+		{[]PosSequenceElementConfig{{Surface: &tokyo}, {POS: &noun}}, 0, 2},
+		{[]PosSequenceElementConfig{{Surface: &kyo}}, 0, -1},
+		{[]PosSequenceElementConfig{{POS: &verb}, {POS: &noun}}, 3, -1},
+	}
+	resultPos := []string{"名詞", "固有名詞", "地名", "一般", "*", "*"}
+	for i, tt := range tests {
+		pattern := tt.pattern
+		plugin := NewJoinPosSequencePlugin(&JoinPosSequencePluginConfig{
+			Rules: &[]PosSequenceRuleConfig{{Pattern: &pattern, ResultPOS: &resultPos}},
+		})
+		err := plugin.SetUp(dict.grammar)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := plugin.match(plugin.rules[0].pattern, path, tt.begin); got != tt.want {
+			t.Errorf("%d: want = %d, got = %d", i, tt.want, got)
+		}
+	}
+}
+
+func TestJoinPosSequencePluginRewrite(t *testing.T) {
+	noun := []string{"名詞"}
+	resultPos := []string{"名詞", "固有名詞", "地名", "一般", "*", "*"}
+	tests := []struct {
+		normalizedForm string
+		want           string
+	}{
+		{normalizedFormConcat, "東京付"},
+		{normalizedFormSurface, "東京附"},
+	}
+	for _, tt := range tests {
+		yes := true
+		normalizedForm := tt.normalizedForm
+		pattern := []PosSequenceElementConfig{{POS: &noun, Repeat: &yes}}
+		dict := newTestDictionary(t, []PathRewritePlugin{
+			NewJoinPosSequencePlugin(&JoinPosSequencePluginConfig{
+				Rules: &[]PosSequenceRuleConfig{{
+					Pattern:        &pattern,
+					ResultPOS:      &resultPos,
+					NormalizedForm: &normalizedForm,
+				}},
+			}),
+		}, nil)
+		ms, err := dict.Create().Tokenize("C", "東京附に")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if ms.Length() != 2 {
+			t.Fatalf("%s: want 2 morphemes, got = %d", tt.normalizedForm, ms.Length())
+		}
+		m := ms.Get(0)
+		if m.Surface() != "東京附" {
+			t.Errorf("%s: invalid surface %s", tt.normalizedForm, m.Surface())
+		}
+		if m.NormalizedForm() != tt.want {
+			t.Errorf("%s: want = %s, got = %s", tt.normalizedForm, tt.want, m.NormalizedForm())
+		}
+		if pos := m.PartOfSpeech(); pos[1] != "固有名詞" {
+			t.Errorf("%s: invalid part of speech %v", tt.normalizedForm, pos)
+		}
+		dict.Close()
+	}
+}
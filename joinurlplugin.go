@@ -0,0 +1,217 @@
+package gosudachi
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+type JoinURLPluginConfig struct {
+	OovPOS  *[]string
+	URL     *bool
+	Email   *bool
+	Mention *bool
+	Hashtag *bool
+}
+
+type urlSpanKind int
+
+const (
+	urlSpan urlSpanKind = iota
+	emailSpan
+	mentionSpan
+	hashtagSpan
+)
+
+var urlSpanPatterns = [...]string{
+	urlSpan:     `(?:https?|ftp)://[0-9A-Za-z\-._~:/?#\[\]@!$&'()*+,;=%]+|www\.[0-9A-Za-z\-]+(?:\.[0-9A-Za-z\-]+)+[0-9A-Za-z\-._~:/?#\[\]@!$&'()*+,;=%]*`,
+	emailSpan:   `[0-9A-Za-z._%+\-]+@[0-9A-Za-z\-]+(?:\.[0-9A-Za-z\-]+)*\.[A-Za-z]{2,}`,
+	mentionSpan: `@[0-9A-Za-z_]+`,
+	hashtagSpan: `#[\p{L}\p{N}_]+`,
+}
+
+// trailing characters that are rather punctuations of the sentence
+const urlTrailingPunct = ".,:;!?'"
+
+// JoinURLPlugin concatenates the nodes covering an URL, an email address,
+// a @mention or a #hashtag into an OOV.
+type JoinURLPlugin struct {
+	config   *JoinURLPluginConfig
+	oovPosId int16
+	re       *regexp.Regexp
+	kinds    []urlSpanKind
+}
+
+func NewJoinURLPlugin(config *JoinURLPluginConfig) *JoinURLPlugin {
+	if config == nil {
+		config = &JoinURLPluginConfig{}
+	}
+	return &JoinURLPlugin{
+		config: config,
+	}
+}
+
+func (p *JoinURLPlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &JoinURLPluginConfig{}
+	}
+	return p.config
+}
+
+func (p *JoinURLPlugin) SetUp(grammar *dictionary.Grammar) error {
+	if p.config.OovPOS == nil || len(*p.config.OovPOS) == 0 {
+		return fmt.Errorf("JoinURLPlugin: oovPOS is not specified")
+	}
+	p.oovPosId = grammar.GetPartOfSpeechId(*p.config.OovPOS)
+	if p.oovPosId < 0 {
+		return fmt.Errorf("JoinURLPlugin: oovPOS is invalid")
+	}
+	enabled := [...]*bool{
+		urlSpan:     p.config.URL,
+		emailSpan:   p.config.Email,
+		mentionSpan: p.config.Mention,
+		hashtagSpan: p.config.Hashtag,
+	}
+	alts := []string{}
+	p.kinds = []urlSpanKind{}
+	for kind, e := range enabled {
+		if e != nil && !*e {
+			continue
+		}
+		alts = append(alts, "("+urlSpanPatterns[kind]+")")
+		p.kinds = append(p.kinds, urlSpanKind(kind))
+	}
+	if len(alts) == 0 {
+		return fmt.Errorf("JoinURLPlugin: all the spans are disabled")
+	}
+	p.re = regexp.MustCompile(strings.Join(alts, "|"))
+	p.config = nil
+	return nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z')
+}
+
+// trimURL drops the punctuations of the sentence following the URL from
+// begin to end, and returns the new end. A closing parenthesis is dropped
+// unless it closes one in the URL.
+func trimURL(b []byte, begin int, end int) int {
+	for end > begin {
+		c := b[end-1]
+		if strings.IndexByte(urlTrailingPunct, c) < 0 &&
+			(c != ')' || bytes.Count(b[begin:end], []byte("(")) >= bytes.Count(b[begin:end], []byte(")"))) {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// findSpans returns the byte ranges of the spans in the text.
+func (p *JoinURLPlugin) findSpans(b []byte) [][2]int {
+	spans := [][2]int{}
+	for _, m := range p.re.FindAllSubmatchIndex(b, -1) {
+		begin, end := m[0], m[1]
+		var kind urlSpanKind
+		for i, k := range p.kinds {
+			if m[2*(i+1)] >= 0 {
+				kind = k
+				break
+			}
+		}
+		// "@" and "#" in the middle of a word do not start a span
+		if (kind == mentionSpan || kind == hashtagSpan) && begin > 0 && isWordByte(b[begin-1]) {
+			continue
+		}
+		if kind == urlSpan {
+			end = trimURL(b, begin, end)
+		}
+		spans = append(spans, [2]int{begin, end})
+	}
+	return spans
+}
+
+// splitNode splits the node at index into the nodes before and after the
+// byte offset at. The one inside the span becomes an OOV of the plugin and
+// the other gets posId, the part of speech of the node before any split.
+func (p *JoinURLPlugin) splitNode(text *InputText, path *[]*LatticeNode, index int, at int, posId int16, spanBefore bool) {
+	node := (*path)[index]
+	before := newURLNode(text, node.Begin, at, posId)
+	after := newURLNode(text, at, node.End, posId)
+	if spanBefore {
+		before.GetWordInfo().PosId = p.oovPosId
+	} else {
+		after.GetWordInfo().PosId = p.oovPosId
+	}
+	tpath := append(*path, nil)
+	copy(tpath[index+2:], tpath[index+1:])
+	tpath[index] = before
+	tpath[index+1] = after
+	*path = tpath
+}
+
+func newURLNode(text *InputText, begin int, end int, posId int16) *LatticeNode {
+	s := text.GetSubstring(begin, end)
+	node := CreateNodeOfOOV()
+	node.SetRange(begin, end)
+	node.SetWordInfo(&dictionary.WordInfo{
+		Surface:        s,
+		HeadwordLength: int16(end - begin),
+		PosId:          posId,
+		NormalizedForm: s,
+		DictionaryForm: s,
+		ReadingForm:    "",
+	})
+	return node
+}
+
+// Rewrite concatenates the nodes of each span. The nodes crossing the
+// boundaries of a span are split at them.
+func (p *JoinURLPlugin) Rewrite(text *InputText, path *[]*LatticeNode, lattice *Lattice) error {
+	i := 0
+	for _, span := range p.findSpans(text.GetByteText()) {
+		for i < len(*path) && (*path)[i].End <= span[0] {
+			i++
+		}
+		if i == len(*path) {
+			break
+		}
+		posId := (*path)[i].GetWordInfo().PosId
+		split := false
+		if (*path)[i].Begin < span[0] {
+			p.splitNode(text, path, i, span[0], posId, false)
+			split = true
+			i++
+		}
+		end := i
+		for end < len(*path) && (*path)[end].End < span[1] {
+			end++
+		}
+		if end == len(*path) {
+			break
+		}
+		if (*path)[end].End > span[1] {
+			// a span inside one node has been split at its beginning and
+			// the node at end is already an OOV of the plugin
+			if !split || end != i {
+				posId = (*path)[end].GetWordInfo().PosId
+			}
+			p.splitNode(text, path, end, span[1], posId, true)
+		}
+		end++
+		if end-i > 1 {
+			_, err := ConcatenateOov(path, i, end, p.oovPosId, lattice)
+			if err != nil {
+				return fmt.Errorf("JoinURLPlugin: %s", err)
+			}
+		} else {
+			(*path)[i] = newURLNode(text, (*path)[i].Begin, (*path)[i].End, p.oovPosId)
+		}
+		i++
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package gosudachi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJoinURLPluginFindSpans(t *testing.T) {
+	dict := newTestDictionary(t, nil, nil)
+	defer dict.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	plugin := NewJoinURLPlugin(&JoinURLPluginConfig{OovPOS: &oovPos})
+	err := plugin.SetUp(dict.grammar)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"詳細はhttps://example.com/a?b=1を参照", []string{"https://example.com/a?b=1"}},
+		{"www.example.co.jp/index.htmlへ", []string{"www.example.co.jp/index.html"}},
+		{"see http://example.com/a.", []string{"http://example.com/a"}},
+		{"(http://example.com/a)です", []string{"http://example.com/a"}},
+		{"(http://example.com/A_(B))", []string{"http://example.com/A_(B)"}},
+		{"連絡はfoo.bar@example.comまで", []string{"foo.bar@example.com"}},
+		{"@user_1さん", []string{"@user_1"}},
+		{"a@user", []string{}},
+		{"#東京タワー です", []string{"#東京タワー"}},
+		{"C#で", []string{}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		b := []byte(tt.text)
+		for _, span := range plugin.findSpans(b) {
+			got = append(got, string(b[span[0]:span[1]]))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: want = %v, got = %v", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestJoinURLPluginRewrite(t *testing.T) {
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	dict := newTestDictionary(t, []PathRewritePlugin{
+		NewJoinURLPlugin(&JoinURLPluginConfig{OovPOS: &oovPos}),
+	}, nil)
+	defer dict.Close()
+	tokenizer := dict.Create()
+
+	// "a都" is a word crossing the end of the URL
+	ms, err := tokenizer.Tokenize("C", "見てhttp://example.com/a都に")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := []string{}
+	for i := 0; i < ms.Length(); i++ {
+		got = append(got, ms.Get(i).Surface())
+	}
+	want := []string{"見", "て", "http://example.com/a", "都", "に"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want = %v, got = %v", want, got)
+	}
+	if pos := ms.Get(2).PartOfSpeech(); !reflect.DeepEqual(pos, oovPos) {
+		t.Errorf("invalid part of speech of the URL: %v", pos)
+	}
+	if !ms.Get(2).IsOOV() {
+		t.Error("the URL is not OOV")
+	}
+}
+
+func TestJoinURLPluginRewriteOneNode(t *testing.T) {
+	dict := newTestDictionary(t, nil, nil)
+	defer dict.Close()
+
+	oovPos := []string{"動詞", "非自立可能", "*", "*", "五段-カ行", "終止形-一般"}
+	plugin := NewJoinURLPlugin(&JoinURLPluginConfig{OovPOS: &oovPos})
+	err := plugin.SetUp(dict.grammar)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	nounPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	nounPosId := dict.grammar.GetPartOfSpeechId(nounPos)
+
+	tests := []struct {
+		text  string
+		nodes [][2]int
+		want  []string
+	}{
+		// the span is one node
+		{"see @user now", [][2]int{{0, 4}, {4, 9}, {9, 13}}, []string{"see ", "@user", " now"}},
+		// the span is strictly inside one node
+		{"x @user y", [][2]int{{0, 9}}, []string{"x ", "@user", " y"}},
+	}
+	for _, tt := range tests {
+		text := NewInputTextBuilder(tt.text, dict.grammar).Build()
+		path := []*LatticeNode{}
+		for _, n := range tt.nodes {
+			path = append(path, newURLNode(text, n[0], n[1], nounPosId))
+		}
+		err := plugin.Rewrite(text, &path, NewLattice(dict.grammar))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.text, err)
+		}
+		got := []string{}
+		for _, node := range path {
+			got = append(got, node.GetWordInfo().Surface)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: want = %v, got = %v", tt.text, tt.want, got)
+			continue
+		}
+		for i, node := range path {
+			pos := nounPos
+			if i == 1 {
+				pos = oovPos
+			}
+			if got := dict.grammar.GetPartOfSpeechString(node.GetWordInfo().PosId); !reflect.DeepEqual(got, pos) {
+				t.Errorf("%s: %s: want = %v, got = %v", tt.text, node.GetWordInfo().Surface, pos, got)
+			}
+		}
+	}
+}
@@ -37,7 +37,10 @@ type LatticeNode struct {
 	isDefined        bool
 	IsOov            bool
 	extraWordInfo    *dictionary.WordInfo
+	wordInfo         *dictionary.WordInfo // read from lexicon
+	readErr          error                // of reading wordInfo
 	lexicon          *dictionary.LexiconSet
+	numericValue     *NumericValue
 }
 
 func NewLatticeNode(lexicon *dictionary.LexiconSet, leftId int16, rightId int16, cost int16, wordId int32) *LatticeNode {
@@ -85,7 +88,26 @@ func (ln *LatticeNode) GetWordInfo() *dictionary.WordInfo {
 	if ln.extraWordInfo != nil {
 		return ln.extraWordInfo
 	}
-	return ln.lexicon.GetWordInfo(ln.wordId)
+	ln.readWordInfo()
+	return ln.wordInfo
+}
+
+// readWordInfo reads the wordInfo of the node from the lexicon once, and
+// returns the error of reading it. The wordInfo which cannot be read is
+// empty.
+func (ln *LatticeNode) readWordInfo() error {
+	if !ln.isDefined || ln.extraWordInfo != nil {
+		return nil
+	}
+	if ln.wordInfo == nil {
+		wi, err := ln.lexicon.GetWordInfo(ln.wordId)
+		if err != nil {
+			wi = &dictionary.WordInfo{DictionaryFormWordId: -1}
+			ln.readErr = err
+		}
+		ln.wordInfo = wi
+	}
+	return ln.readErr
 }
 
 func (ln *LatticeNode) SetWordInfo(wordInfo *dictionary.WordInfo) {
@@ -93,6 +115,16 @@ func (ln *LatticeNode) SetWordInfo(wordInfo *dictionary.WordInfo) {
 	ln.isDefined = true
 }
 
+// GetNumericValue returns the structured value set by JoinNumericPlugin,
+// or nil.
+func (ln *LatticeNode) GetNumericValue() *NumericValue {
+	return ln.numericValue
+}
+
+func (ln *LatticeNode) SetNumericValue(value *NumericValue) {
+	ln.numericValue = value
+}
+
 func (ln *LatticeNode) GetPathCost() int {
 	return int(ln.cost)
 }
@@ -154,6 +186,19 @@ func (l *Lattice) resize(size int) {
 	l.eosNode.End = size
 }
 
+// readError returns an error of reading the wordInfo of a node in the
+// lattice, or nil.
+func (l *Lattice) readError() error {
+	for _, nodes := range l.endLists {
+		for _, n := range nodes {
+			if n.readErr != nil {
+				return n.readErr
+			}
+		}
+	}
+	return nil
+}
+
 func (l *Lattice) clear() {
 	for i := 1; i < len(l.endLists); i++ {
 		l.endLists[i] = l.endLists[i][:0]
@@ -0,0 +1,403 @@
+package gosudachi
+
+import (
+	"html"
+	"strings"
+)
+
+type MarkupInputTextPluginConfig struct {
+	DecodeEntities *bool
+	DropRuby       *bool
+	Markdown       *bool
+}
+
+// elements whose contents are removed with the tags
+var markupDroppedElements = []string{"script", "style"}
+
+// elements of the ruby annotations
+var markupRubyElements = []string{"rt", "rp"}
+
+// MarkupInputTextPlugin removes the HTML tags and comments, and decodes
+// the character references. With markdown, it also removes the Markdown
+// syntax: emphases, links, images, code spans and fences, headings,
+// block quotes, list markers and backslash escapes. The offsets of the
+// remaining text are kept, so that morphemes point to the original markup.
+type MarkupInputTextPlugin struct {
+	config         *MarkupInputTextPluginConfig
+	decodeEntities bool
+	dropRuby       bool
+	markdown       bool
+}
+
+func NewMarkupInputTextPlugin(config *MarkupInputTextPluginConfig) *MarkupInputTextPlugin {
+	if config == nil {
+		config = &MarkupInputTextPluginConfig{}
+	}
+	return &MarkupInputTextPlugin{
+		config: config,
+	}
+}
+
+func (p *MarkupInputTextPlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &MarkupInputTextPluginConfig{}
+	}
+	return p.config
+}
+
+func (p *MarkupInputTextPlugin) SetUp() error {
+	p.decodeEntities = p.config.DecodeEntities == nil || *p.config.DecodeEntities
+	p.dropRuby = p.config.DropRuby == nil || *p.config.DropRuby
+	p.markdown = p.config.Markdown != nil && *p.config.Markdown
+	p.config = nil
+	return nil
+}
+
+type markupReplacement struct {
+	begin int
+	end   int
+	runes []rune
+}
+
+func isASCIILetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func hasPrefixFold(runes []rune, i int, prefix string) bool {
+	if i+len(prefix) > len(runes) {
+		return false
+	}
+	return strings.EqualFold(string(runes[i:i+len(prefix)]), prefix)
+}
+
+func indexFold(runes []rune, i int, s string) int {
+	for ; i+len(s) <= len(runes); i++ {
+		if hasPrefixFold(runes, i, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// tagEnd returns the end of the tag at i and its lower case name, or -1
+// if runes[i] does not start a tag.
+func tagEnd(runes []rune, i int) (int, string, bool) {
+	if hasPrefixFold(runes, i, "<!--") {
+		e := indexFold(runes, i+4, "-->")
+		if e < 0 {
+			return -1, "", false
+		}
+		return e + 3, "", false
+	}
+	j := i + 1
+	closing := false
+	if j < len(runes) && (runes[j] == '/' || runes[j] == '!' || runes[j] == '?') {
+		closing = runes[j] == '/'
+		j++
+	}
+	if j >= len(runes) || !isASCIILetter(runes[j]) {
+		return -1, "", false
+	}
+	nameBegin := j
+	for j < len(runes) && (isASCIILetter(runes[j]) || ('0' <= runes[j] && runes[j] <= '9')) {
+		j++
+	}
+	name := strings.ToLower(string(runes[nameBegin:j]))
+	var quote rune
+	for ; j < len(runes); j++ {
+		switch {
+		case quote != 0:
+			if runes[j] == quote {
+				quote = 0
+			}
+		case runes[j] == '"' || runes[j] == '\'':
+			quote = runes[j]
+		case runes[j] == '>':
+			return j + 1, name, closing
+		}
+	}
+	return -1, "", false
+}
+
+// entityEnd returns the end of the character reference at i, or -1.
+func entityEnd(runes []rune, i int) int {
+	for j := i + 1; j < len(runes) && j-i <= 32; j++ {
+		r := runes[j]
+		if r == ';' {
+			if j == i+1 {
+				return -1
+			}
+			return j + 1
+		}
+		if !isASCIILetter(r) && !('0' <= r && r <= '9') && !(r == '#' && j == i+1) {
+			return -1
+		}
+	}
+	return -1
+}
+
+func (p *MarkupInputTextPlugin) dropsContent(name string) bool {
+	for _, e := range markupDroppedElements {
+		if name == e {
+			return true
+		}
+	}
+	if p.dropRuby {
+		for _, e := range markupRubyElements {
+			if name == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *MarkupInputTextPlugin) scan(runes []rune) []markupReplacement {
+	replacements := []markupReplacement{}
+	md := &markdownScanner{runes: runes, closers: map[int]int{}}
+	lineStart := true
+	inFence := false
+	for i := 0; i < len(runes); {
+		if p.markdown {
+			if lineStart {
+				if end, ok := md.fence(i); ok {
+					replacements = append(replacements, markupReplacement{i, end, nil})
+					inFence = !inFence
+					i = end
+					continue
+				}
+				if !inFence {
+					if end := md.lineMarker(i); end > i {
+						replacements = append(replacements, markupReplacement{i, end, nil})
+						i = end
+						continue
+					}
+				}
+			}
+			if inFence {
+				lineStart = runes[i] == '\n'
+				i++
+				continue
+			}
+			if n, ok := md.closers[i]; ok {
+				replacements = append(replacements, markupReplacement{i, i + n, nil})
+				delete(md.closers, i)
+				i += n
+				lineStart = false
+				continue
+			}
+			if rs, next, ok := md.inline(i); ok {
+				replacements = append(replacements, rs...)
+				i = next
+				lineStart = false
+				continue
+			}
+		}
+		lineStart = runes[i] == '\n'
+		switch runes[i] {
+		case '<':
+			end, name, closing := tagEnd(runes, i)
+			if end < 0 {
+				break
+			}
+			if !closing && p.dropsContent(name) {
+				if e := indexFold(runes, end, "</"+name); e >= 0 {
+					if ce, _, _ := tagEnd(runes, e); ce >= 0 {
+						end = ce
+					}
+				}
+			}
+			replacements = append(replacements, markupReplacement{i, end, nil})
+			i = end
+			continue
+		case '&':
+			if !p.decodeEntities {
+				break
+			}
+			end := entityEnd(runes, i)
+			if end < 0 {
+				break
+			}
+			entity := string(runes[i:end])
+			decoded := html.UnescapeString(entity)
+			if decoded == entity {
+				break
+			}
+			replacements = append(replacements, markupReplacement{i, end, []rune(decoded)})
+			i = end
+			continue
+		}
+		i++
+	}
+	return replacements
+}
+
+// markdownScanner finds the Markdown syntax. closers has the lengths of
+// the closing delimiters at their positions, which are found together
+// with the opening ones.
+type markdownScanner struct {
+	runes   []rune
+	closers map[int]int
+}
+
+func (md *markdownScanner) lineEnd(i int) int {
+	for i < len(md.runes) && md.runes[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+// run returns the length of the run of c at i.
+func (md *markdownScanner) run(i int, c rune) int {
+	n := 0
+	for i+n < len(md.runes) && md.runes[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// fence returns the end of the code fence line at i including the line
+// feed.
+func (md *markdownScanner) fence(i int) (int, bool) {
+	c := md.runes[i]
+	if (c != '`' && c != '~') || md.run(i, c) < 3 {
+		return i, false
+	}
+	end := md.lineEnd(i)
+	if end < len(md.runes) {
+		end++
+	}
+	return end, true
+}
+
+// lineMarker returns the end of the heading, block quote or list marker
+// at the beginning of the line at i, or i.
+func (md *markdownScanner) lineMarker(i int) int {
+	runes := md.runes
+	j := i
+	for j < len(runes) && j-i < 3 && runes[j] == ' ' {
+		j++
+	}
+	if j >= len(runes) {
+		return i
+	}
+	switch c := runes[j]; {
+	case c == '#':
+		n := md.run(j, '#')
+		j += n
+		if n > 6 {
+			return i
+		}
+	case c == '>':
+		j++
+	case c == '-' || c == '*' || c == '+':
+		j++
+		if j >= len(runes) || runes[j] != ' ' {
+			return i
+		}
+	case '0' <= c && c <= '9':
+		for j < len(runes) && '0' <= runes[j] && runes[j] <= '9' {
+			j++
+		}
+		if j >= len(runes) || (runes[j] != '.' && runes[j] != ')') {
+			return i
+		}
+		j++
+		if j >= len(runes) || runes[j] != ' ' {
+			return i
+		}
+	default:
+		return i
+	}
+	for j < len(runes) && runes[j] == ' ' {
+		j++
+	}
+	return j
+}
+
+func isASCIIPunct(r rune) bool {
+	return r < 0x80 && strings.ContainsRune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", r)
+}
+
+func isASCIIAlnum(r rune) bool {
+	return isASCIILetter(r) || ('0' <= r && r <= '9')
+}
+
+// inline returns the replacements of the inline syntax opened at i and
+// where the scan continues.
+func (md *markdownScanner) inline(i int) ([]markupReplacement, int, bool) {
+	runes := md.runes
+	switch c := runes[i]; c {
+	case '\\':
+		if i+1 < len(runes) && isASCIIPunct(runes[i+1]) {
+			// the escaped character is kept as it is
+			return []markupReplacement{{i, i + 1, nil}}, i + 2, true
+		}
+	case '`':
+		n := md.run(i, '`')
+		end := md.lineEnd(i)
+		for j := i + n; j < end; {
+			m := md.run(j, '`')
+			if m == n {
+				// the content of a code span is kept as it is
+				return []markupReplacement{{i, i + n, nil}, {j, j + n, nil}}, j + n, true
+			}
+			j += m + 1
+		}
+	case '!', '[':
+		open := i
+		if c == '!' {
+			if i+1 >= len(runes) || runes[i+1] != '[' {
+				break
+			}
+			open++
+		}
+		end := md.lineEnd(i)
+		for j := open + 1; j+1 < end; j++ {
+			if runes[j] == ']' {
+				if runes[j+1] != '(' {
+					break
+				}
+				for k := j + 2; k < end; k++ {
+					if runes[k] == ')' {
+						md.closers[j] = k + 1 - j
+						return []markupReplacement{{i, open + 1, nil}}, open + 1, true
+					}
+				}
+				break
+			}
+		}
+	case '*', '_', '~':
+		n := md.run(i, c)
+		if n > 2 || (c == '~' && n != 2) {
+			break
+		}
+		if i+n >= len(runes) || runes[i+n] == ' ' || runes[i+n] == '\n' {
+			break
+		}
+		if c == '_' && i > 0 && isASCIIAlnum(runes[i-1]) {
+			break
+		}
+		end := md.lineEnd(i)
+		for j := i + n + 1; j+n <= end; j++ {
+			if md.run(j, c) != n || runes[j-1] == ' ' || runes[j-1] == c {
+				continue
+			}
+			if c == '_' && j+n < len(runes) && isASCIIAlnum(runes[j+n]) {
+				continue
+			}
+			md.closers[j] = n
+			return []markupReplacement{{i, i + n, nil}}, i + n, true
+		}
+	}
+	return nil, i, false
+}
+
+func (p *MarkupInputTextPlugin) Rewrite(builder *InputTextBuilder) error {
+	offset := 0
+	for _, r := range p.scan(builder.GetText()) {
+		builder.Replace(r.begin+offset, r.end+offset, r.runes)
+		offset += len(r.runes) - (r.end - r.begin)
+	}
+	return nil
+}
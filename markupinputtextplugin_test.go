@@ -0,0 +1,110 @@
+package gosudachi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarkupInputTextPlugin(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		config *MarkupInputTextPluginConfig
+		text   string
+		want   string
+	}{
+		{nil, "<p>東京&amp;大阪</p>", "東京&大阪"},
+		{nil, "<ruby>漢字<rp>(</rp><rt>かんじ</rt><rp>)</rp></ruby>", "漢字"},
+		{&MarkupInputTextPluginConfig{DropRuby: &disabled}, "<ruby>漢字<rt>かんじ</rt></ruby>", "漢字かんじ"},
+		{&MarkupInputTextPluginConfig{DecodeEntities: &disabled}, "a&lt;b", "a&lt;b"},
+		{nil, "<a href=\"x>y\">リンク</a><!-- <b> -->", "リンク"},
+		{nil, "<script>var a = 1 < 2;</script>本文", "本文"},
+		{nil, "1 < 2 &unknown; &#12354;", "1 < 2 &unknown; あ"},
+	}
+	for _, tt := range tests {
+		plugin := NewMarkupInputTextPlugin(tt.config)
+		err := plugin.SetUp()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		builder := NewInputTextBuilder(tt.text, nil)
+		err = plugin.Rewrite(builder)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got := builder.GetText()
+		if string(got) != tt.want {
+			t.Errorf("%s: want = %s, got = %s", tt.text, tt.want, string(got))
+		}
+	}
+
+	enabled := true
+	markdown := &MarkupInputTextPluginConfig{Markdown: &enabled}
+	for _, tt := range []struct {
+		text string
+		want string
+	}{
+		{"**東京**と*大阪*と__京都__", "東京と大阪と京都"},
+		{"~~削除~~済み", "削除済み"},
+		{"[リンク](http://example.com/)を![画像](a.png)", "リンクを画像"},
+		{"`a*b*c`を実行", "a*b*cを実行"},
+		{"snake_case_name", "snake_case_name"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"\\*強調しない\\*", "*強調しない*"},
+		{"# 見出し\n> 引用\n- 項目\n1. 番号", "見出し\n引用\n項目\n番号"},
+		{"```go\n*a* [b](c)\n```\n**本文**", "*a* [b](c)\n本文"},
+		{"<b>**太字**</b>", "太字"},
+	} {
+		plugin := NewMarkupInputTextPlugin(markdown)
+		err := plugin.SetUp()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		builder := NewInputTextBuilder(tt.text, nil)
+		err = plugin.Rewrite(builder)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got := builder.GetText()
+		if string(got) != tt.want {
+			t.Errorf("%s: want = %s, got = %s", tt.text, tt.want, string(got))
+		}
+	}
+
+	// Markdown is not removed by default
+	plugin := NewMarkupInputTextPlugin(nil)
+	plugin.SetUp()
+	builder := NewInputTextBuilder("**東京**", nil)
+	plugin.Rewrite(builder)
+	if string(builder.GetText()) != "**東京**" {
+		t.Errorf("Markdown is removed by default: %s", string(builder.GetText()))
+	}
+
+	// the offsets point to the original markup
+	plugin = NewMarkupInputTextPlugin(nil)
+	plugin.SetUp()
+	builder = NewInputTextBuilder("<b>東京</b>&amp;", nil)
+	plugin.Rewrite(builder)
+	if !reflect.DeepEqual(builder.textOffsets, []int{3, 4, 9, 14}) {
+		t.Errorf("invalid offsets: %v", builder.textOffsets)
+	}
+	if !reflect.DeepEqual(builder.textEndOffsets, []int{4, 5, 14}) {
+		t.Errorf("invalid end offsets: %v", builder.textEndOffsets)
+	}
+}
+
+func TestMarkupInputTextPluginMarkdownOffsets(t *testing.T) {
+	enabled := true
+	plugin := NewMarkupInputTextPlugin(&MarkupInputTextPluginConfig{Markdown: &enabled})
+	plugin.SetUp()
+	builder := NewInputTextBuilder("[東京](u)**都**", nil)
+	plugin.Rewrite(builder)
+	if string(builder.GetText()) != "東京都" {
+		t.Fatalf("invalid text: %s", string(builder.GetText()))
+	}
+	if !reflect.DeepEqual(builder.textOffsets[:3], []int{1, 2, 9}) {
+		t.Errorf("invalid offsets: %v", builder.textOffsets)
+	}
+	if !reflect.DeepEqual(builder.textEndOffsets, []int{2, 3, 10}) {
+		t.Errorf("invalid end offsets: %v", builder.textEndOffsets)
+	}
+}
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/msnoigrs/gosudachi/internal/command"
+)
+
+func main() {
+	command.Single("mecabdicbuilder", command.BuildMeCab)
+}
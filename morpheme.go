@@ -49,6 +49,13 @@ func (m *Morpheme) ReadingForm() string {
 	return wi.ReadingForm
 }
 
+// NumericValue returns the structured value of a numeric expression, or
+// nil. It is available when JoinNumericPlugin is configured with
+// enableStructuredValue.
+func (m *Morpheme) NumericValue() *NumericValue {
+	return m.list.GetNumericValue(m.index)
+}
+
 func (m *Morpheme) Split(mode string) *MorphemeList {
 	wi := m.GetWordInfo()
 	return m.list.Split(mode, m.index, wi)
@@ -103,7 +110,7 @@ func (l *MorphemeList) GetBegin(index int) int {
 }
 
 func (l *MorphemeList) GetEnd(index int) int {
-	return l.inputText.GetOriginalIndex(l.path[index].End)
+	return l.inputText.GetOriginalEndIndex(l.path[index].End)
 }
 
 func (l *MorphemeList) GetSurface(index int) string {
@@ -144,6 +151,10 @@ func (l *MorphemeList) Split(mode string, index int, wi *dictionary.WordInfo) *M
 	return NewMorphemeList(l.inputText, l.grammar, l.lexicon, nodes)
 }
 
+func (l *MorphemeList) GetNumericValue(index int) *NumericValue {
+	return l.path[index].GetNumericValue()
+}
+
 func (l *MorphemeList) IsOOV(index int) bool {
 	return l.path[index].IsOOV()
 }
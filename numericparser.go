@@ -82,7 +82,6 @@ func (n *stringNumber) add(t *stringNumber) bool {
 		if t.point >= 0 {
 			n.point = len(n.significand) + t.point
 		}
-		_ = t.String()
 		n.significand = append(n.significand, t.significand...)
 		n.scale = t.scale
 		return true
@@ -0,0 +1,34 @@
+package gosudachi
+
+import (
+	"testing"
+)
+
+func TestNumericParser(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want string
+	}{
+		{"一千二百", "1200"},
+		{"二十万五千", "205000"},
+		{"1億2345万6789", "123456789"},
+		{"2万5千3百", "25300"},
+		{"1,234", "1234"},
+	} {
+		parser := newNumericParser()
+		ok := true
+		for _, c := range tc.text {
+			if !parser.append(c) {
+				ok = false
+				break
+			}
+		}
+		if !ok || !parser.done() {
+			t.Errorf("%s: fail to parse", tc.text)
+			continue
+		}
+		if got := parser.getNormalized(); got != tc.want {
+			t.Errorf("%s: want = %s, got = %s", tc.text, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package gosudachi
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+type NumericKind int
+
+const (
+	NumericNumber NumericKind = iota
+	NumericFraction
+	NumericPercentage
+	NumericRange
+	NumericCurrency
+	NumericQuantity
+)
+
+func (k NumericKind) String() string {
+	switch k {
+	case NumericNumber:
+		return "number"
+	case NumericFraction:
+		return "fraction"
+	case NumericPercentage:
+		return "percentage"
+	case NumericRange:
+		return "range"
+	case NumericCurrency:
+		return "currency"
+	case NumericQuantity:
+		return "quantity"
+	}
+	return "unknown"
+}
+
+// NumericValue is the structured value of a numeric expression.
+type NumericValue struct {
+	Kind NumericKind
+	// Value is the number. It is the lower bound for NumericRange and
+	// the number of percents for NumericPercentage.
+	Value float64
+	// Max is the upper bound for NumericRange.
+	Max float64
+	// Unit is the unit or currency of the expression, "%" for
+	// NumericPercentage, or empty.
+	Unit string
+}
+
+func (v *NumericValue) String() string {
+	s := strconv.FormatFloat(v.Value, 'f', -1, 64)
+	if v.Kind == NumericRange {
+		s += "~" + strconv.FormatFloat(v.Max, 'f', -1, 64)
+	}
+	return s + v.Unit
+}
+
+var (
+	defaultCurrencyUnits = []string{
+		"円", "万円", "億円", "銭", "ドル", "米ドル", "ユーロ", "ポンド", "元", "ウォン",
+	}
+	defaultQuantityUnits = []string{
+		"km", "m", "cm", "mm", "kg", "g", "mg", "t", "l", "ml", "ha",
+		"キロ", "キロメートル", "メートル", "センチ", "センチメートル", "ミリ", "ミリメートル",
+		"キログラム", "グラム", "トン", "リットル", "ミリリットル",
+	}
+	prefixCurrencyUnits = map[string]string{
+		"$": "ドル",
+		"¥": "円",
+		"€": "ユーロ",
+		"£": "ポンド",
+	}
+	minusSigns   = []string{"マイナス", "-", "−", "▲"}
+	rangeSymbols = []string{"〜", "~", "～", "-", "−"}
+	percentSigns = []string{"%", "パーセント"}
+)
+
+// maxUnitNodes is the maximum number of nodes a unit spans.
+const maxUnitNodes = 3
+
+// matchSurface returns the end of the nodes from begin whose surfaces
+// concatenate to s, or -1.
+func matchSurface(path []*LatticeNode, begin int, s string) int {
+	rest := s
+	for i := begin; i < len(path) && i < begin+maxUnitNodes; i++ {
+		surface := path[i].GetWordInfo().Surface
+		if len(surface) > len(rest) || rest[:len(surface)] != surface {
+			return -1
+		}
+		rest = rest[len(surface):]
+		if rest == "" {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// matchAny returns the longest of candidates that matches the nodes from
+// begin and the end of the nodes.
+func matchAny(path []*LatticeNode, begin int, candidates []string) (string, int) {
+	matched := ""
+	end := -1
+	for _, c := range candidates {
+		if len(c) <= len(matched) {
+			continue
+		}
+		if e := matchSurface(path, begin, c); e >= 0 {
+			matched = c
+			end = e
+		}
+	}
+	return matched, end
+}
+
+// numericValueOf returns the value of a node that JoinNumericPlugin
+// treats as a number.
+func (p *JoinNumericPlugin) numericValueOf(node *LatticeNode, parser *numericParser) (float64, bool) {
+	wi := node.GetWordInfo()
+	if wi.PosId != p.numericPosId {
+		return 0, false
+	}
+	parser.clear()
+	for _, c := range wi.NormalizedForm {
+		if !parser.append(c) {
+			return 0, false
+		}
+	}
+	if !parser.done() {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(parser.getNormalized(), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// canPrecedeMinusSign tells whether sign after node is a minus sign.
+// A hyphen is a minus sign only after whitespace, punctuation or a word
+// which is neither a number nor ends with a letter or a digit, so that
+// "ABC-5" is not -5.
+func (p *JoinNumericPlugin) canPrecedeMinusSign(node *LatticeNode, sign string, parser *numericParser) bool {
+	if _, isNum := p.numericValueOf(node, parser); isNum {
+		return false
+	}
+	if sign != "-" {
+		return true
+	}
+	surface := node.GetWordInfo().Surface
+	r, _ := utf8.DecodeLastRuneInString(surface)
+	return r == utf8.RuneError || !(unicode.IsDigit(r) || unicode.In(r, unicode.Latin))
+}
+
+// structure finds the numeric expression around the number at index and
+// concatenates its nodes into a node with a NumericValue. It returns
+// the index of the concatenated node.
+func (p *JoinNumericPlugin) structure(path *[]*LatticeNode, index int, lattice *Lattice, parser *numericParser) (int, error) {
+	tpath := *path
+	v, ok := p.numericValueOf(tpath[index], parser)
+	if !ok {
+		return index, nil
+	}
+	value := &NumericValue{
+		Kind:  NumericNumber,
+		Value: v,
+	}
+
+	begin := index
+	end := index + 1
+	if begin > 0 {
+		if sign, e := matchAny(tpath, begin-1, minusSigns); e == begin {
+			if begin < 2 || p.canPrecedeMinusSign(tpath[begin-2], sign, parser) {
+				begin--
+				value.Value = -value.Value
+			}
+		}
+	}
+	if begin > 0 {
+		if unit, ok := prefixCurrencyUnits[tpath[begin-1].GetWordInfo().Surface]; ok {
+			begin--
+			value.Kind = NumericCurrency
+			value.Unit = unit
+		}
+	}
+
+	// 三分の一
+	if e := matchSurface(tpath, end, "分の"); e >= 0 && e < len(tpath) {
+		if n, ok := p.numericValueOf(tpath[e], parser); ok && value.Value != 0 {
+			value.Kind = NumericFraction
+			value.Value = n / value.Value
+			end = e + 1
+		}
+	}
+
+	if value.Kind == NumericNumber {
+		// 五割, 3割5分, 3割5分2厘
+		if e := matchSurface(tpath, end, "割"); e >= 0 {
+			value.Kind = NumericPercentage
+			value.Unit = "%"
+			value.Value *= 10
+			end = e
+			for _, u := range []struct {
+				unit  string
+				scale float64
+			}{{"分", 1}, {"厘", 0.1}} {
+				if end >= len(tpath) {
+					break
+				}
+				n, ok := p.numericValueOf(tpath[end], parser)
+				if !ok {
+					break
+				}
+				e := matchSurface(tpath, end+1, u.unit)
+				if e < 0 {
+					break
+				}
+				value.Value += n * u.scale
+				end = e
+			}
+		} else if _, e := matchAny(tpath, end, percentSigns); e >= 0 {
+			value.Kind = NumericPercentage
+			value.Unit = "%"
+			end = e
+		}
+	}
+
+	// 10〜20
+	if value.Kind == NumericNumber || value.Kind == NumericCurrency {
+		if _, e := matchAny(tpath, end, rangeSymbols); e >= 0 && e < len(tpath) {
+			if n, ok := p.numericValueOf(tpath[e], parser); ok {
+				value.Kind = NumericRange
+				value.Max = n
+				end = e + 1
+			}
+		}
+	}
+
+	// 10〜20%
+	if value.Kind == NumericRange && value.Unit == "" {
+		if _, e := matchAny(tpath, end, percentSigns); e >= 0 {
+			value.Unit = "%"
+			end = e
+		}
+	}
+
+	if value.Unit == "" && end < len(tpath) {
+		if unit, e := matchAny(tpath, end, p.currencyUnits); e >= 0 {
+			if value.Kind == NumericNumber {
+				value.Kind = NumericCurrency
+			}
+			value.Unit = unit
+			end = e
+		} else if unit, e := matchAny(tpath, end, p.quantityUnits); e >= 0 {
+			if value.Kind == NumericNumber {
+				value.Kind = NumericQuantity
+			}
+			value.Unit = unit
+			end = e
+		}
+	}
+
+	if end-begin == 1 {
+		tpath[index].numericValue = value
+		return index, nil
+	}
+	node, err := ConcatenateNodes(path, begin, end, lattice, "")
+	if err != nil {
+		return index, err
+	}
+	node.GetWordInfo().PosId = p.numericPosId
+	node.numericValue = value
+	return begin, nil
+}
@@ -0,0 +1,107 @@
+package gosudachi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+const testNumericPosId = 1
+
+// newTestPath returns the path of the nodes of the surfaces separated by
+// "/". A surface beginning with "#" is a number whose normalized form
+// follows ":", such as "#1万:10000".
+func newTestPath(s string) []*LatticeNode {
+	path := []*LatticeNode{}
+	offset := 0
+	for _, token := range strings.Split(s, "/") {
+		surface := token
+		normalizedForm := token
+		var posId int16
+		if strings.HasPrefix(token, "#") {
+			posId = testNumericPosId
+			surface = token[1:]
+			normalizedForm = surface
+			if i := strings.Index(surface, ":"); i >= 0 {
+				normalizedForm = surface[i+1:]
+				surface = surface[:i]
+			}
+		}
+		node := &LatticeNode{}
+		node.SetRange(offset, offset+len(surface))
+		node.SetWordInfo(&dictionary.WordInfo{
+			Surface:        surface,
+			HeadwordLength: int16(len(surface)),
+			PosId:          posId,
+			NormalizedForm: normalizedForm,
+			DictionaryForm: surface,
+			ReadingForm:    surface,
+		})
+		path = append(path, node)
+		offset += len(surface)
+	}
+	return path
+}
+
+func TestJoinNumericPluginStructure(t *testing.T) {
+	tests := []struct {
+		path    string
+		surface string
+		kind    NumericKind
+		value   string
+	}{
+		{"#三:3/分/の/#一:1", "三分の一", NumericFraction, "0.3333333333333333"},
+		{"#3/割/#5/分", "3割5分", NumericPercentage, "35%"},
+		{"#3/割/#5/分/#2/厘", "3割5分2厘", NumericPercentage, "35.2%"},
+		{"#5/割", "5割", NumericPercentage, "50%"},
+		{"#12/%", "12%", NumericPercentage, "12%"},
+		{"マイナス/#5", "マイナス5", NumericNumber, "-5"},
+		{"-/#5", "-5", NumericNumber, "-5"},
+		{"気温/-/#5/度", "-5", NumericNumber, "-5"},
+		{"、/-/#5", "-5", NumericNumber, "-5"},
+		{"ABC/-/#5", "5", NumericNumber, "5"},
+		{"ver/-/#2", "2", NumericNumber, "2"},
+		{"#1万:10000/円", "1万円", NumericCurrency, "10000円"},
+		{"$/#100", "$100", NumericCurrency, "100ドル"},
+		{"#5/km", "5km", NumericQuantity, "5km"},
+		{"#10/〜/#20", "10〜20", NumericRange, "10~20"},
+		{"#10/〜/#20/%", "10〜20%", NumericRange, "10~20%"},
+		{"#10/〜/#20/万円", "10〜20万円", NumericRange, "10~20万円"},
+		{"#7", "7", NumericNumber, "7"},
+	}
+	p := &JoinNumericPlugin{
+		numericPosId:  testNumericPosId,
+		currencyUnits: defaultCurrencyUnits,
+		quantityUnits: defaultQuantityUnits,
+	}
+	parser := newNumericParser()
+	for _, tt := range tests {
+		path := newTestPath(tt.path)
+		var value *NumericValue
+		for i := 0; i < len(path); i++ {
+			index, err := p.structure(&path, i, nil, parser)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", tt.path, err)
+			}
+			i = index
+			if v := path[index].GetNumericValue(); v != nil {
+				value = v
+				if got := path[index].GetWordInfo().Surface; got != tt.surface {
+					t.Errorf("%s: want surface = %s, got = %s", tt.path, tt.surface, got)
+				}
+				break
+			}
+		}
+		if value == nil {
+			t.Errorf("%s: no numeric value", tt.path)
+			continue
+		}
+		if value.Kind != tt.kind {
+			t.Errorf("%s: want kind = %s, got = %s", tt.path, tt.kind, value.Kind)
+		}
+		if got := value.String(); got != tt.value {
+			t.Errorf("%s: want value = %s, got = %s", tt.path, tt.value, got)
+		}
+	}
+}
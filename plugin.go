@@ -15,6 +15,17 @@ type BaseConfig struct {
 	CharacterDefinitionFile string
 	UserDict                []string
 	Utf16String             bool
+	Storage                 string // "mmap", "heap" or "pread"
+	StorageAdvice           string // "normal", "random", "sequential" or "willneed"
+	StoragePopulate         bool
+}
+
+func (config *BaseConfig) storageOptions() *dictionary.StorageOptions {
+	return &dictionary.StorageOptions{
+		Storage:  config.Storage,
+		Advice:   config.StorageAdvice,
+		Populate: config.StoragePopulate,
+	}
 }
 
 type PluginMaker interface {
@@ -33,40 +44,35 @@ type MakeEditConnectionCostPluginFunc func(n string) EditConnectionCostPlugin
 type MakeOovProviderPluginFunc func(n string) OovProviderPlugin
 type MakePathRewritePluginFunc func(n string) PathRewritePlugin
 
+// DefMakeInputTextPlugin makes the InputTextPlugin registered as k.
 func DefMakeInputTextPlugin(k string) InputTextPlugin {
-	switch k {
-	case "DefaultInputTextPlugin", "com.worksap.nlp.sudachi.DefaultInputTextPlugin":
-		return NewDefaultInputTextPlugin(nil)
-	case "ProlongedSoundMarkInputTextPlugin", "com.worksap.nlp.sudachi.ProlongedSoundMarkInputTextPlugin":
-		return NewProlongedSoundMarkInputTextPlugin(nil)
+	if f, ok := inputTextPluginRegistry.lookup(k).(InputTextPluginFactory); ok {
+		return f()
 	}
 	return nil
 }
 
+// DefMakeEditConnectionCostPlugin makes the EditConnectionCostPlugin
+// registered as k.
 func DefMakeEditConnectionCostPlugin(k string) EditConnectionCostPlugin {
-	switch k {
-	case "InhibitConnectionPlugin", "com.worksap.nlp.sudachi.InhibitConnectionPlugin":
-		return NewInhibitConnectionPlugin([]*[]int{})
+	if f, ok := editConnectionCostPluginRegistry.lookup(k).(EditConnectionCostPluginFactory); ok {
+		return f()
 	}
 	return nil
 }
 
+// DefMakeOovProviderPlugin makes the OovProviderPlugin registered as k.
 func DefMakeOovProviderPlugin(k string) OovProviderPlugin {
-	switch k {
-	case "MeCabOovProviderPlugin", "com.worksap.nlp.sudachi.MeCabOovProviderPlugin":
-		return NewMeCabOovProviderPlugin(nil)
-	case "SimpleOovProviderPlugin", "com.worksap.nlp.sudachi.SimpleOovProviderPlugin":
-		return NewSimpleOovProviderPlugin(nil)
+	if f, ok := oovProviderPluginRegistry.lookup(k).(OovProviderPluginFactory); ok {
+		return f()
 	}
 	return nil
 }
 
+// DefMakePathRewritePlugin makes the PathRewritePlugin registered as k.
 func DefMakePathRewritePlugin(k string) PathRewritePlugin {
-	switch k {
-	case "JoinNumericPlugin", "com.worksap.nlp.sudachi.JoinNumericPlugin":
-		return NewJoinNumericPlugin(nil)
-	case "JoinKatakanaOovPlugin", "com.worksap.nlp.sudachi.JoinKatakanaOovPlugin":
-		return NewJoinKatakanaOovPlugin(nil)
+	if f, ok := pathRewritePluginRegistry.lookup(k).(PathRewritePluginFactory); ok {
+		return f()
 	}
 	return nil
 }
@@ -0,0 +1,179 @@
+package gosudachi
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type InputTextPluginFactory func() InputTextPlugin
+type OovProviderPluginFactory func() OovProviderPlugin
+type PathRewritePluginFactory func() PathRewritePlugin
+type EditConnectionCostPluginFactory func() EditConnectionCostPlugin
+
+// pluginRegistry maps the names of plugins of a kind to their factories.
+// A factory is stored as interface{} and asserted by the typed wrappers.
+type pluginRegistry struct {
+	kind      string
+	factories map[string]interface{}
+	canonical map[string]string
+}
+
+func newPluginRegistry(kind string) *pluginRegistry {
+	return &pluginRegistry{
+		kind:      kind,
+		factories: map[string]interface{}{},
+		canonical: map[string]string{},
+	}
+}
+
+func (r *pluginRegistry) register(name string, factory interface{}, aliases []string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, n := range append([]string{name}, aliases...) {
+		if n == "" {
+			panic(fmt.Sprintf("%s: plugin name is empty", r.kind))
+		}
+		if _, dup := r.factories[n]; dup {
+			panic(fmt.Sprintf("%s: %s is registered twice", r.kind, n))
+		}
+	}
+	for _, n := range append([]string{name}, aliases...) {
+		r.factories[n] = factory
+		r.canonical[n] = name
+	}
+}
+
+func (r *pluginRegistry) lookup(name string) interface{} {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return r.factories[name]
+}
+
+func (r *pluginRegistry) canonicalName(name string) (string, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := r.canonical[name]
+	return c, ok
+}
+
+func (r *pluginRegistry) names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	ret := []string{}
+	for n, c := range r.canonical {
+		if n == c {
+			ret = append(ret, n)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+var (
+	registryMu                       sync.RWMutex
+	inputTextPluginRegistry          = newPluginRegistry("InputTextPlugin")
+	oovProviderPluginRegistry        = newPluginRegistry("OovProviderPlugin")
+	pathRewritePluginRegistry        = newPluginRegistry("PathRewritePlugin")
+	editConnectionCostPluginRegistry = newPluginRegistry("EditConnectionCostPlugin")
+)
+
+// RegisterInputTextPlugin makes an InputTextPlugin available by name and
+// aliases in the settings. It panics if any of the names is already
+// registered.
+func RegisterInputTextPlugin(name string, factory InputTextPluginFactory, aliases ...string) {
+	inputTextPluginRegistry.register(name, factory, aliases)
+}
+
+// RegisterOovProviderPlugin is the OovProviderPlugin version of
+// RegisterInputTextPlugin.
+func RegisterOovProviderPlugin(name string, factory OovProviderPluginFactory, aliases ...string) {
+	oovProviderPluginRegistry.register(name, factory, aliases)
+}
+
+// RegisterPathRewritePlugin is the PathRewritePlugin version of
+// RegisterInputTextPlugin.
+func RegisterPathRewritePlugin(name string, factory PathRewritePluginFactory, aliases ...string) {
+	pathRewritePluginRegistry.register(name, factory, aliases)
+}
+
+// RegisterEditConnectionCostPlugin is the EditConnectionCostPlugin version
+// of RegisterInputTextPlugin.
+func RegisterEditConnectionCostPlugin(name string, factory EditConnectionCostPluginFactory, aliases ...string) {
+	editConnectionCostPluginRegistry.register(name, factory, aliases)
+}
+
+// InputTextPluginNames returns the registered names except aliases.
+func InputTextPluginNames() []string {
+	return inputTextPluginRegistry.names()
+}
+
+func OovProviderPluginNames() []string {
+	return oovProviderPluginRegistry.names()
+}
+
+func PathRewritePluginNames() []string {
+	return pathRewritePluginRegistry.names()
+}
+
+func EditConnectionCostPluginNames() []string {
+	return editConnectionCostPluginRegistry.names()
+}
+
+// builtinAliases returns the aliases of a built-in plugin: the Java class
+// name and the Go type name.
+func builtinAliases(name string) []string {
+	return []string{
+		"com.worksap.nlp.sudachi." + name,
+		"github.com/msnoigrs/gosudachi." + name,
+	}
+}
+
+func init() {
+	RegisterInputTextPlugin("DefaultInputTextPlugin", func() InputTextPlugin {
+		return NewDefaultInputTextPlugin(nil)
+	}, builtinAliases("DefaultInputTextPlugin")...)
+	RegisterInputTextPlugin("ProlongedSoundMarkInputTextPlugin", func() InputTextPlugin {
+		return NewProlongedSoundMarkInputTextPlugin(nil)
+	}, builtinAliases("ProlongedSoundMarkInputTextPlugin")...)
+	RegisterInputTextPlugin("IterationMarkInputTextPlugin", func() InputTextPlugin {
+		return NewIterationMarkInputTextPlugin(nil)
+	}, builtinAliases("IterationMarkInputTextPlugin")...)
+	RegisterInputTextPlugin("MarkupInputTextPlugin", func() InputTextPlugin {
+		return NewMarkupInputTextPlugin(nil)
+	}, builtinAliases("MarkupInputTextPlugin")...)
+
+	RegisterOovProviderPlugin("MeCabOovProviderPlugin", func() OovProviderPlugin {
+		return NewMeCabOovProviderPlugin(nil)
+	}, builtinAliases("MeCabOovProviderPlugin")...)
+	RegisterOovProviderPlugin("SimpleOovProviderPlugin", func() OovProviderPlugin {
+		return NewSimpleOovProviderPlugin(nil)
+	}, builtinAliases("SimpleOovProviderPlugin")...)
+	RegisterOovProviderPlugin("RegexOovProviderPlugin", func() OovProviderPlugin {
+		return NewRegexOovProviderPlugin(nil)
+	}, builtinAliases("RegexOovProviderPlugin")...)
+
+	RegisterPathRewritePlugin("JoinNumericPlugin", func() PathRewritePlugin {
+		return NewJoinNumericPlugin(nil)
+	}, builtinAliases("JoinNumericPlugin")...)
+	RegisterPathRewritePlugin("JoinKatakanaOovPlugin", func() PathRewritePlugin {
+		return NewJoinKatakanaOovPlugin(nil)
+	}, builtinAliases("JoinKatakanaOovPlugin")...)
+	RegisterPathRewritePlugin("JoinURLPlugin", func() PathRewritePlugin {
+		return NewJoinURLPlugin(nil)
+	}, builtinAliases("JoinURLPlugin")...)
+	RegisterPathRewritePlugin("JoinPosSequencePlugin", func() PathRewritePlugin {
+		return NewJoinPosSequencePlugin(nil)
+	}, builtinAliases("JoinPosSequencePlugin")...)
+	RegisterPathRewritePlugin("JoinDateTimePlugin", func() PathRewritePlugin {
+		return NewJoinDateTimePlugin(nil)
+	}, builtinAliases("JoinDateTimePlugin")...)
+
+	RegisterEditConnectionCostPlugin("InhibitConnectionPlugin", func() EditConnectionCostPlugin {
+		return NewInhibitConnectionPlugin([]*[]int{})
+	}, builtinAliases("InhibitConnectionPlugin")...)
+	RegisterEditConnectionCostPlugin("EditConnectionPlugin", func() EditConnectionCostPlugin {
+		return NewEditConnectionPlugin(nil)
+	}, builtinAliases("EditConnectionPlugin")...)
+}
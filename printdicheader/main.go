@@ -1,31 +1,9 @@
 package main
 
 import (
-	"flag"
-	"fmt"
-	"os"
-
-	"github.com/msnoigrs/gosudachi/dictionary"
+	"github.com/msnoigrs/gosudachi/internal/command"
 )
 
 func main() {
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, `Usage of %s:
-	%s file
-`, os.Args[0], os.Args[0])
-		flag.PrintDefaults()
-	}
-
-	flag.Parse()
-
-	if len(flag.Args()) == 0 {
-		flag.Usage()
-		os.Exit(1)
-	}
-
-	err := dictionary.PrintHeader(flag.Arg(0), os.Stdout)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	command.Single("printdicheader", command.Header)
 }
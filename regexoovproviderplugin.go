@@ -0,0 +1,122 @@
+package gosudachi
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+type RegexOovPatternConfig struct {
+	Pattern *string
+	OovPos  *[]string
+	LeftId  *int16
+	RightId *int16
+	Cost    *int16
+}
+
+type RegexOovProviderPluginConfig struct {
+	Patterns *[]RegexOovPatternConfig
+}
+
+type regexOov struct {
+	re *regexp.Regexp
+	oov
+}
+
+// RegexOovProviderPlugin provides an OOV node for each pattern that
+// matches the text at the offset, whether or not other words are found.
+type RegexOovProviderPlugin struct {
+	config   *RegexOovProviderPluginConfig
+	patterns []*regexOov
+}
+
+func NewRegexOovProviderPlugin(config *RegexOovProviderPluginConfig) *RegexOovProviderPlugin {
+	if config == nil {
+		config = &RegexOovProviderPluginConfig{}
+	}
+	return &RegexOovProviderPlugin{
+		config: config,
+	}
+}
+
+func (p *RegexOovProviderPlugin) GetConfigStruct() interface{} {
+	if p.config == nil {
+		p.config = &RegexOovProviderPluginConfig{}
+	}
+	return p.config
+}
+
+func (p *RegexOovProviderPlugin) SetUp(grammar *dictionary.Grammar) error {
+	if p.config.Patterns == nil {
+		return fmt.Errorf("RegexOovProviderPlugin: patterns is not specified")
+	}
+	p.patterns = make([]*regexOov, 0, len(*p.config.Patterns))
+	for i, pc := range *p.config.Patterns {
+		if pc.Pattern == nil {
+			return fmt.Errorf("RegexOovProviderPlugin: pattern is not specified in patterns[%d]", i)
+		}
+		if pc.OovPos == nil {
+			return fmt.Errorf("RegexOovProviderPlugin: oovPOS is not specified in patterns[%d]", i)
+		}
+		if pc.LeftId == nil {
+			return fmt.Errorf("RegexOovProviderPlugin: leftId is not specified in patterns[%d]", i)
+		}
+		if pc.RightId == nil {
+			return fmt.Errorf("RegexOovProviderPlugin: rightId is not specified in patterns[%d]", i)
+		}
+		if pc.Cost == nil {
+			return fmt.Errorf("RegexOovProviderPlugin: cost is not specified in patterns[%d]", i)
+		}
+		// anchor the pattern at the offset given to ProvideOOV
+		re, err := regexp.Compile(`^(?:` + *pc.Pattern + `)`)
+		if err != nil {
+			return fmt.Errorf("RegexOovProviderPlugin: %s in patterns[%d]", err, i)
+		}
+		if len(*pc.OovPos) == 0 {
+			return fmt.Errorf("RegexOovProviderPlugin: oovPOS is zero length in patterns[%d]", i)
+		}
+		posId := grammar.GetPartOfSpeechId(*pc.OovPos)
+		if posId < 0 {
+			return fmt.Errorf("RegexOovProviderPlugin: oovPOS is invalid in patterns[%d]", i)
+		}
+		p.patterns = append(p.patterns, &regexOov{
+			re: re,
+			oov: oov{
+				leftId:  *pc.LeftId,
+				rightId: *pc.RightId,
+				cost:    *pc.Cost,
+				posId:   posId,
+			},
+		})
+	}
+	p.config = nil
+	return nil
+}
+
+func (p *RegexOovProviderPlugin) ProvideOOV(inputText *InputText, offset int, hasOtherWords bool) ([]*LatticeNode, error) {
+	nodes := []*LatticeNode{}
+	text := inputText.GetByteText()[offset:]
+	for _, pattern := range p.patterns {
+		loc := pattern.re.FindIndex(text)
+		if loc == nil || loc[1] == 0 || loc[1] > math.MaxInt16 {
+			continue
+		}
+		length := loc[1]
+		s := inputText.GetSubstring(offset, offset+length)
+		node := CreateNodeOfOOV()
+		node.SetParameter(pattern.leftId, pattern.rightId, pattern.cost)
+		wi := &dictionary.WordInfo{
+			Surface:        s,
+			HeadwordLength: int16(length),
+			PosId:          pattern.posId,
+			NormalizedForm: s,
+			DictionaryForm: s,
+			ReadingForm:    "",
+		}
+		node.SetWordInfo(wi)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,72 @@
+package gosudachi
+
+import (
+	"testing"
+)
+
+func TestRegexOovProviderPlugin(t *testing.T) {
+	dict := newTestDictionary(t, nil, nil)
+	defer dict.Close()
+
+	digitPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	alphaPos := []string{"名詞", "固有名詞", "地名", "一般", "*", "*"}
+	digitPattern, alphaPattern := "[0-9]+", "[a-z]+"
+	var digitId, digitCost, alphaId, alphaCost int16 = 2, 100, 1, 200
+	plugin := NewRegexOovProviderPlugin(&RegexOovProviderPluginConfig{
+		Patterns: &[]RegexOovPatternConfig{
+			{Pattern: &digitPattern, OovPos: &digitPos, LeftId: &digitId, RightId: &digitId, Cost: &digitCost},
+			{Pattern: &alphaPattern, OovPos: &alphaPos, LeftId: &alphaId, RightId: &alphaId, Cost: &alphaCost},
+		},
+	})
+	err := plugin.SetUp(dict.grammar)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	input := NewInputTextBuilder("東京123abc", dict.grammar).Build()
+	tests := []struct {
+		offset  int
+		surface string
+		pos     []string
+		id      int16
+		cost    int16
+	}{
+		// the patterns match later in the text, but not at the offset
+		{0, "", nil, 0, 0},
+		{6, "123", digitPos, digitId, digitCost},
+		{7, "23", digitPos, digitId, digitCost},
+		{9, "abc", alphaPos, alphaId, alphaCost},
+	}
+	for _, tt := range tests {
+		nodes, err := GetOOV(plugin, input, tt.offset, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if tt.surface == "" {
+			if len(nodes) != 0 {
+				t.Errorf("offset %d: want no node, got = %d nodes", tt.offset, len(nodes))
+			}
+			continue
+		}
+		if len(nodes) != 1 {
+			t.Fatalf("offset %d: want 1 node, got = %d", tt.offset, len(nodes))
+		}
+		n := nodes[0]
+		wi := n.GetWordInfo()
+		if wi.Surface != tt.surface {
+			t.Errorf("offset %d: want surface = %s, got = %s", tt.offset, tt.surface, wi.Surface)
+		}
+		if n.Begin != tt.offset || n.End != tt.offset+len(tt.surface) {
+			t.Errorf("offset %d: invalid range %d-%d", tt.offset, n.Begin, n.End)
+		}
+		if wi.PosId != dict.grammar.GetPartOfSpeechId(tt.pos) {
+			t.Errorf("offset %d: invalid pos %d", tt.offset, wi.PosId)
+		}
+		if n.leftId != tt.id || n.rightId != tt.id || n.cost != tt.cost {
+			t.Errorf("offset %d: invalid parameters %d %d %d", tt.offset, n.leftId, n.rightId, n.cost)
+		}
+		if !n.IsOOV() {
+			t.Errorf("offset %d: not OOV", tt.offset)
+		}
+	}
+}
@@ -3,7 +3,7 @@
 SRC_DIR="${PWD}"
 BUILD_DIR="${PWD}"
 DIST="${BUILD_DIR}/dist"
-CMDDIRS="gosudachicli dicbuilder userdicbuilder printdic printdicheader dicconv"
+CMDDIRS="gosudachi gosudachicli dicbuilder userdicbuilder mecabdicbuilder printdic printdicheader dicconv"
 
 build() {
     cd "${SRC_DIR}/$1"
@@ -0,0 +1,233 @@
+// Package server provides the HTTP/JSON API of the tokenizer.
+//
+//	GET  /health          {"status":"ok"}
+//	POST /tokenize        {"text":"...","mode":"C","splits":false}
+//	POST /tokenize/batch  {"texts":["...",...],"mode":"C","splits":false}
+//	GET  /lookup?text=... the words whose headword is text
+//
+// The morphemes are in the same form as the json format of gosudachicli.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/formatter"
+)
+
+// DefaultMaxBodySize is the default limit of the size of a request body.
+const DefaultMaxBodySize = 10 << 20
+
+// Server handles the requests with a JapaneseDictionary shared by the
+// tokenizers of the concurrent requests.
+type Server struct {
+	// MaxBodySize is the limit of the size of a request body.
+	MaxBodySize int64
+	// MaxBatchSize is the limit of the number of the texts of a batch
+	// request. 0 means no limit.
+	MaxBatchSize int
+
+	dict       *gosudachi.JapaneseDictionary
+	tokenizers sync.Pool
+	mux        *http.ServeMux
+}
+
+func New(dict *gosudachi.JapaneseDictionary) *Server {
+	s := &Server{
+		MaxBodySize: DefaultMaxBodySize,
+		dict:        dict,
+		mux:         http.NewServeMux(),
+	}
+	s.tokenizers.New = func() interface{} {
+		return dict.Create()
+	}
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/tokenize", s.handleTokenize)
+	s.mux.HandleFunc("/tokenize/batch", s.handleTokenizeBatch)
+	s.mux.HandleFunc("/lookup", s.handleLookup)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type TokenizeRequest struct {
+	Text   string `json:"text"`
+	Mode   string `json:"mode"`
+	Splits bool   `json:"splits"`
+}
+
+type BatchRequest struct {
+	Texts  []string `json:"texts"`
+	Mode   string   `json:"mode"`
+	Splits bool     `json:"splits"`
+}
+
+type BatchResponse struct {
+	Results []*formatter.JSONSentence `json:"results"`
+}
+
+type LookupWord struct {
+	Surface        string   `json:"surface"`
+	POS            []string `json:"pos"`
+	NormalizedForm string   `json:"normalizedForm"`
+	DictionaryForm string   `json:"dictionaryForm"`
+	ReadingForm    string   `json:"readingForm"`
+	DictionaryId   int      `json:"dictionaryId"`
+	WordId         int32    `json:"wordId"`
+	LeftId         int16    `json:"leftId"`
+	RightId        int16    `json:"rightId"`
+	Cost           int16    `json:"cost"`
+}
+
+type LookupResponse struct {
+	Text  string        `json:"text"`
+	Words []*LookupWord `json:"words"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &ErrorResponse{Error: err.Error()})
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	for _, m := range methods {
+		w.Header().Add("Allow", m)
+	}
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	return false
+}
+
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body := http.MaxBytesReader(w, r.Body, s.MaxBodySize)
+	err := json.NewDecoder(body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %s", err))
+		return false
+	}
+	return true
+}
+
+func validMode(w http.ResponseWriter, mode *string) bool {
+	if *mode == "" {
+		*mode = "C"
+	}
+	if !gosudachi.ValidMode(*mode) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid mode: %s", *mode))
+		return false
+	}
+	return true
+}
+
+func (s *Server) tokenize(mode string, texts []string, splits bool) ([]*formatter.JSONSentence, error) {
+	tokenizer := s.tokenizers.Get().(*gosudachi.JapaneseTokenizer)
+	defer s.tokenizers.Put(tokenizer)
+	ret := make([]*formatter.JSONSentence, len(texts))
+	for i, text := range texts {
+		ms, err := tokenizer.Tokenize(mode, text)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = formatter.NewJSONSentence(text, ms, splits)
+	}
+	return ret, nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleTokenize(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req TokenizeRequest
+	if !s.decode(w, r, &req) || !validMode(w, &req.Mode) {
+		return
+	}
+	results, err := s.tokenize(req.Mode, []string{req.Text}, req.Splits)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, results[0])
+}
+
+func (s *Server) handleTokenizeBatch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req BatchRequest
+	if !s.decode(w, r, &req) || !validMode(w, &req.Mode) {
+		return
+	}
+	if s.MaxBatchSize > 0 && len(req.Texts) > s.MaxBatchSize {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("too many texts: %d > %d", len(req.Texts), s.MaxBatchSize))
+		return
+	}
+	results, err := s.tokenize(req.Mode, req.Texts, req.Splits)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &BatchResponse{Results: results})
+}
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	text := r.URL.Query().Get("text")
+	if text == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("text is not specified"))
+		return
+	}
+	found, err := s.dict.Lookup(text)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res := &LookupResponse{
+		Text:  text,
+		Words: make([]*LookupWord, len(found)),
+	}
+	for i, word := range found {
+		wi := word.WordInfo
+		res.Words[i] = &LookupWord{
+			Surface:        wi.Surface,
+			POS:            s.dict.GetPartOfSpeechString(wi.PosId),
+			NormalizedForm: wi.NormalizedForm,
+			DictionaryForm: wi.DictionaryForm,
+			ReadingForm:    wi.ReadingForm,
+			DictionaryId:   word.DictionaryId,
+			WordId:         word.WordId,
+			LeftId:         word.LeftId,
+			RightId:        word.RightId,
+			Cost:           word.Cost,
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+const testLexicon = `東京,0,0,2816,東京,名詞,固有名詞,地名,一般,*,*,トウキョウ,東京,*,A,*,*,*
+都,0,0,2914,都,名詞,普通名詞,一般,*,*,*,ト,都,*,A,*,*,*
+行く,0,0,5105,行く,動詞,非自立可能,*,*,五段-カ行,終止形-一般,イク,行く,*,A,*,*,*
+`
+
+func newTestDictionary(t *testing.T) *gosudachi.JapaneseDictionary {
+	f, err := ioutil.TempFile("", "server")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	hb, err := dictionary.NewDictionaryHeader(dictionary.SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dicbuilder := dictionary.NewDictionaryBuilder(int64(len(hb)), nil, false)
+	store := dictionary.NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(testLexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader("1 1\n0 0 0\n"), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	var id, cost int16 = 0, 10000
+	dict, err := gosudachi.NewJapaneseDictionary(
+		&gosudachi.BaseConfig{SystemDict: f.Name(), Storage: "heap"},
+		[]gosudachi.InputTextPlugin{},
+		[]gosudachi.OovProviderPlugin{
+			gosudachi.NewSimpleOovProviderPlugin(&gosudachi.SimpleOovProviderPluginConfig{
+				OovPos:  &oovPos,
+				LeftId:  &id,
+				RightId: &id,
+				Cost:    &cost,
+			}),
+		},
+		[]gosudachi.PathRewritePlugin{},
+		[]gosudachi.EditConnectionCostPlugin{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return dict
+}
+
+func TestServer(t *testing.T) {
+	dict := newTestDictionary(t)
+	defer dict.Close()
+	s := New(dict)
+	s.MaxBatchSize = 2
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	post := func(path string, body string, v interface{}) int {
+		res, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer res.Body.Close()
+		if v != nil {
+			if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}
+		return res.StatusCode
+	}
+
+	res, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("health: status %d", res.StatusCode)
+	}
+
+	var sentence struct {
+		Text      string
+		Morphemes []struct {
+			Surface string
+			Begin   int
+			End     int
+			OOV     bool
+		}
+	}
+	if status := post("/tokenize", `{"text":"東京都に行く"}`, &sentence); status != http.StatusOK {
+		t.Fatalf("tokenize: status %d", status)
+	}
+	var surfaces []string
+	for _, m := range sentence.Morphemes {
+		surfaces = append(surfaces, m.Surface)
+	}
+	if got := strings.Join(surfaces, "/"); got != "東京/都/に/行く" {
+		t.Errorf("tokenize: %s", got)
+	}
+	if m := sentence.Morphemes[2]; m.Begin != 3 || m.End != 4 || !m.OOV {
+		t.Errorf("tokenize: %+v", m)
+	}
+
+	var batch struct {
+		Results []struct {
+			Text string
+		}
+	}
+	if status := post("/tokenize/batch", `{"texts":["東京","行く"],"mode":"A"}`, &batch); status != http.StatusOK {
+		t.Fatalf("batch: status %d", status)
+	}
+	if len(batch.Results) != 2 || batch.Results[1].Text != "行く" {
+		t.Errorf("batch: %+v", batch)
+	}
+	if status := post("/tokenize/batch", `{"texts":["a","b","c"]}`, nil); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("batch: status %d for too many texts", status)
+	}
+	if status := post("/tokenize", `{"text":"東京","mode":"D"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("tokenize: status %d for an invalid mode", status)
+	}
+	if status := post("/tokenize", `{`, nil); status != http.StatusBadRequest {
+		t.Errorf("tokenize: status %d for an invalid body", status)
+	}
+
+	res, err = http.Get(ts.URL + "/lookup?text=" + "%E6%9D%B1%E4%BA%AC")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var lookup LookupResponse
+	err = json.NewDecoder(res.Body).Decode(&lookup)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(lookup.Words) != 1 || lookup.Words[0].ReadingForm != "トウキョウ" {
+		t.Errorf("lookup: %+v", lookup)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				var got struct {
+					Morphemes []interface{}
+				}
+				if status := post("/tokenize", `{"text":"東京都に行く東京"}`, &got); status != http.StatusOK || len(got.Morphemes) != 5 {
+					t.Errorf("concurrent tokenize: status %d, %d morphemes", status, len(got.Morphemes))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,187 @@
+package gosudachi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// EffectiveSettings is the settings as a dictionary uses them, in the
+// form ParseSettingsJSON reads. The paths are absolute, and a plugin is
+// named by its registered name.
+type EffectiveSettings struct {
+	Path                     string                   `json:"path,omitempty"`
+	SystemDict               string                   `json:"systemDict"`
+	CharacterDefinitionFile  string                   `json:"characterDefinitionFile,omitempty"`
+	UserDict                 []string                 `json:"userDict,omitempty"`
+	Utf16String              bool                     `json:"utf16String"`
+	Storage                  string                   `json:"storage,omitempty"`
+	StorageAdvice            string                   `json:"storageAdvice,omitempty"`
+	StoragePopulate          bool                     `json:"storagePopulate,omitempty"`
+	InputTextPlugin          []map[string]interface{} `json:"inputTextPlugin"`
+	OovProviderPlugin        []map[string]interface{} `json:"oovProviderPlugin"`
+	PathRewritePlugin        []map[string]interface{} `json:"pathRewritePlugin"`
+	EditConnectionCostPlugin []map[string]interface{} `json:"editConnectionCostPlugin"`
+}
+
+// checkedPlugins collects the plugins of a setting made by CheckSettings.
+type checkedPlugins struct {
+	where     string
+	registry  *pluginRegistry
+	effective []map[string]interface{}
+	plugins   []Plugin
+	// indexes maps the index of a plugin in plugins to the index of the
+	// setting of it.
+	indexes []int
+}
+
+func (c *checkedPlugins) make(raws []json.RawMessage, makeproc func(name string) Plugin) []error {
+	problems := []error{}
+	c.effective = []map[string]interface{}{}
+	for i, raw := range raws {
+		name, plugin, err := makePlugin(c.registry.kind, raw, makeproc)
+		if err == nil {
+			var e map[string]interface{}
+			e, err = effectivePluginSettings(plugin)
+			if err == nil {
+				if canonical, ok := c.registry.canonicalName(name); ok {
+					name = canonical
+				}
+				e["class"] = name
+				c.effective = append(c.effective, e)
+				c.plugins = append(c.plugins, plugin)
+				c.indexes = append(c.indexes, i)
+				continue
+			}
+		} else if plugin == nil && name != "" && c.registry.lookup(name) == nil {
+			err = fmt.Errorf("%s (one of %s)", err, strings.Join(c.registry.names(), ", "))
+		}
+		problems = append(problems, fmt.Errorf("%s[%d]: %s", c.where, i, err))
+	}
+	return problems
+}
+
+// effectivePluginSettings returns the config of plugin as a JSON object
+// without the fields not set.
+func effectivePluginSettings(plugin Plugin) (map[string]interface{}, error) {
+	config := plugin.GetConfigStruct()
+	if config == nil {
+		return map[string]interface{}{}, nil
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	e := map[string]interface{}{}
+	err = json.Unmarshal(b, &e)
+	if err != nil {
+		return nil, err
+	}
+	// the keys are in the lower camel case of the settings files
+	ret := map[string]interface{}{}
+	for k, v := range e {
+		if v != nil {
+			ret[strings.ToLower(k[:1])+k[1:]] = v
+		}
+	}
+	return ret, nil
+}
+
+func absPath(path string) string {
+	if path == "" {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// CheckSettings makes the plugins of settings and sets up a dictionary
+// with them as NewJapaneseDictionary does, but goes on after an error to
+// find all the problems. It returns the effective settings and the
+// problems, which are prefixed with the setting of the plugin if any.
+// The dictionary is closed before CheckSettings returns.
+func CheckSettings(settings *SettingsJSON) (*EffectiveSettings, []error) {
+	config := settings.GetBaseConfig()
+	effective := &EffectiveSettings{
+		Path:                    settings.path,
+		SystemDict:              absPath(config.SystemDict),
+		CharacterDefinitionFile: absPath(config.CharacterDefinitionFile),
+		Utf16String:             config.Utf16String,
+		Storage:                 config.Storage,
+		StorageAdvice:           config.StorageAdvice,
+		StoragePopulate:         config.StoragePopulate,
+	}
+	for _, ud := range config.UserDict {
+		effective.UserDict = append(effective.UserDict, absPath(ud))
+	}
+
+	problems := []error{}
+	inputText := &checkedPlugins{where: "inputTextPlugin", registry: inputTextPluginRegistry}
+	problems = append(problems, inputText.make(settings.inputTextPlugin, func(name string) Plugin {
+		if p := DefMakeInputTextPlugin(name); p != nil {
+			return p
+		}
+		return nil
+	})...)
+	oovProvider := &checkedPlugins{where: "oovProviderPlugin", registry: oovProviderPluginRegistry}
+	problems = append(problems, oovProvider.make(settings.oovProviderPlugin, func(name string) Plugin {
+		if p := DefMakeOovProviderPlugin(name); p != nil {
+			return p
+		}
+		return nil
+	})...)
+	pathRewrite := &checkedPlugins{where: "pathRewritePlugin", registry: pathRewritePluginRegistry}
+	problems = append(problems, pathRewrite.make(settings.pathRewritePlugin, func(name string) Plugin {
+		if p := DefMakePathRewritePlugin(name); p != nil {
+			return p
+		}
+		return nil
+	})...)
+	editConnectionCost := &checkedPlugins{where: "editConnectionCostPlugin", registry: editConnectionCostPluginRegistry}
+	problems = append(problems, editConnectionCost.make(settings.editConnectionCostPlugin, func(name string) Plugin {
+		if p := DefMakeEditConnectionCostPlugin(name); p != nil {
+			return p
+		}
+		return nil
+	})...)
+	effective.InputTextPlugin = inputText.effective
+	effective.OovProviderPlugin = oovProvider.effective
+	effective.PathRewritePlugin = pathRewrite.effective
+	effective.EditConnectionCostPlugin = editConnectionCost.effective
+
+	inputTextPlugins := make([]InputTextPlugin, len(inputText.plugins))
+	for i, p := range inputText.plugins {
+		inputTextPlugins[i] = p.(InputTextPlugin)
+	}
+	oovProviderPlugins := make([]OovProviderPlugin, len(oovProvider.plugins))
+	for i, p := range oovProvider.plugins {
+		oovProviderPlugins[i] = p.(OovProviderPlugin)
+	}
+	pathRewritePlugins := make([]PathRewritePlugin, len(pathRewrite.plugins))
+	for i, p := range pathRewrite.plugins {
+		pathRewritePlugins[i] = p.(PathRewritePlugin)
+	}
+	editConnectionCostPlugins := make([]EditConnectionCostPlugin, len(editConnectionCost.plugins))
+	for i, p := range editConnectionCost.plugins {
+		editConnectionCostPlugins[i] = p.(EditConnectionCostPlugin)
+	}
+	checked := map[string]*checkedPlugins{
+		inputText.where:          inputText,
+		oovProvider.where:        oovProvider,
+		pathRewrite.where:        pathRewrite,
+		editConnectionCost.where: editConnectionCost,
+	}
+
+	d := setUpJapaneseDictionary(config, inputTextPlugins, oovProviderPlugins, pathRewritePlugins, editConnectionCostPlugins, func(where string, i int, err error) bool {
+		if c, ok := checked[where]; ok {
+			err = fmt.Errorf("%s[%d]: %s", where, c.indexes[i], err)
+		}
+		problems = append(problems, err)
+		return true
+	})
+	d.Close()
+	return effective, problems
+}
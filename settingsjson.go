@@ -30,6 +30,9 @@ func (settings *SettingsJSON) ParseSettingsJSON(defpath string, reader io.Reader
 		SystemDict               *string
 		CharacterDefinitionFile  *string
 		Utf16String              *bool
+		Storage                  *string
+		StorageAdvice            *string
+		StoragePopulate          *bool
 		UserDict                 *[]string
 		InputTextPlugin          *[]json.RawMessage
 		OovProviderPlugin        *[]json.RawMessage
@@ -56,6 +59,15 @@ func (settings *SettingsJSON) ParseSettingsJSON(defpath string, reader io.Reader
 	if internalBaseConfig.Utf16String != nil {
 		settings.Utf16String = *internalBaseConfig.Utf16String
 	}
+	if internalBaseConfig.Storage != nil {
+		settings.Storage = *internalBaseConfig.Storage
+	}
+	if internalBaseConfig.StorageAdvice != nil {
+		settings.StorageAdvice = *internalBaseConfig.StorageAdvice
+	}
+	if internalBaseConfig.StoragePopulate != nil {
+		settings.StoragePopulate = *internalBaseConfig.StoragePopulate
+	}
 	if internalBaseConfig.UserDict != nil {
 		for _, ud := range *internalBaseConfig.UserDict {
 			settings.UserDict = append(settings.UserDict, settings.getPath(ud))
@@ -84,126 +96,111 @@ func (settings *SettingsJSON) getPath(path string) string {
 	return filepath.Join(settings.path, path)
 }
 
-func (settings *SettingsJSON) GetInputTextPluginArray(makeproc MakeInputTextPluginFunc) ([]InputTextPlugin, error) {
-	ret := []InputTextPlugin{}
+// makePlugin makes the plugin of a setting with makeproc, and sets the
+// config of it.
+func makePlugin(kind string, raw json.RawMessage, makeproc func(name string) Plugin) (string, Plugin, error) {
 	pname := &struct {
 		Class *string
 		Name  *string
 	}{}
+	err := json.Unmarshal(raw, pname)
+	if err != nil {
+		return "", nil, err
+	}
+	var name string
+	if pname.Class != nil {
+		name = *pname.Class
+	}
+	if pname.Name != nil {
+		name = *pname.Name
+	}
+	plugin := makeproc(name)
+	if plugin == nil {
+		return name, nil, fmt.Errorf("%s: %s is unknown", kind, name)
+	}
+	err = json.Unmarshal(raw, plugin.GetConfigStruct())
+	if err != nil {
+		return name, nil, err
+	}
+	return name, plugin, nil
+}
+
+func (settings *SettingsJSON) GetInputTextPluginArray(makeproc MakeInputTextPluginFunc) ([]InputTextPlugin, error) {
+	if makeproc == nil {
+		makeproc = DefMakeInputTextPlugin
+	}
+	ret := []InputTextPlugin{}
 	for _, raw := range settings.inputTextPlugin {
-		err := json.Unmarshal(raw, pname)
-		if err != nil {
-			return ret, err
-		}
-		var name string
-		if pname.Class != nil {
-			name = *pname.Class
-		}
-		if pname.Name != nil {
-			name = *pname.Name
-		}
-		plugin := makeproc(name)
-		if plugin == nil {
-			return ret, fmt.Errorf("InputTextPlugin: %s is unknown", name)
-		}
-		err = json.Unmarshal(raw, plugin.GetConfigStruct())
+		_, plugin, err := makePlugin("InputTextPlugin", raw, func(name string) Plugin {
+			if p := makeproc(name); p != nil {
+				return p
+			}
+			return nil
+		})
 		if err != nil {
 			return ret, err
 		}
-		ret = append(ret, plugin)
+		ret = append(ret, plugin.(InputTextPlugin))
 	}
 	return ret, nil
 }
 
 func (settings *SettingsJSON) GetOovProviderPluginArray(makeproc MakeOovProviderPluginFunc) ([]OovProviderPlugin, error) {
+	if makeproc == nil {
+		makeproc = DefMakeOovProviderPlugin
+	}
 	ret := []OovProviderPlugin{}
-	pname := &struct {
-		Class *string
-		Name  *string
-	}{}
 	for _, raw := range settings.oovProviderPlugin {
-		err := json.Unmarshal(raw, pname)
+		_, plugin, err := makePlugin("OovProviderPlugin", raw, func(name string) Plugin {
+			if p := makeproc(name); p != nil {
+				return p
+			}
+			return nil
+		})
 		if err != nil {
 			return ret, err
 		}
-		var name string
-		if pname.Class != nil {
-			name = *pname.Class
-		}
-		if pname.Name != nil {
-			name = *pname.Name
-		}
-		plugin := makeproc(name)
-		if plugin == nil {
-			return ret, fmt.Errorf("OovProviderPlugin: %s is unknown", name)
-		}
-		err = json.Unmarshal(raw, plugin.GetConfigStruct())
-		if err != nil {
-			return ret, err
-		}
-		ret = append(ret, plugin)
+		ret = append(ret, plugin.(OovProviderPlugin))
 	}
 	return ret, nil
 }
 
 func (settings *SettingsJSON) GetEditConnectionCostPluginArray(makeproc MakeEditConnectionCostPluginFunc) ([]EditConnectionCostPlugin, error) {
+	if makeproc == nil {
+		makeproc = DefMakeEditConnectionCostPlugin
+	}
 	ret := []EditConnectionCostPlugin{}
-	pname := &struct {
-		Class *string
-		Name  *string
-	}{}
 	for _, raw := range settings.editConnectionCostPlugin {
-		err := json.Unmarshal(raw, pname)
-		if err != nil {
-			return ret, err
-		}
-		var name string
-		if pname.Class != nil {
-			name = *pname.Class
-		}
-		if pname.Name != nil {
-			name = *pname.Name
-		}
-		plugin := makeproc(name)
-		if plugin == nil {
-			return ret, fmt.Errorf("EditConnectionCostPlugin: %s is unknown", name)
-		}
-		err = json.Unmarshal(raw, plugin.GetConfigStruct())
+		_, plugin, err := makePlugin("EditConnectionCostPlugin", raw, func(name string) Plugin {
+			if p := makeproc(name); p != nil {
+				return p
+			}
+			return nil
+		})
 		if err != nil {
 			return ret, err
 		}
-		ret = append(ret, plugin)
+		ret = append(ret, plugin.(EditConnectionCostPlugin))
 	}
 	return ret, nil
 }
 
 func (settings *SettingsJSON) GetPathRewritePluginArray(makeproc MakePathRewritePluginFunc) ([]PathRewritePlugin, error) {
+	if makeproc == nil {
+		makeproc = DefMakePathRewritePlugin
+	}
 	ret := []PathRewritePlugin{}
-	pname := &struct {
-		Class *string
-		Name  *string
-	}{}
 	for _, raw := range settings.pathRewritePlugin {
-		err := json.Unmarshal(raw, pname)
-		if err != nil {
-			return ret, err
-		}
-		var name string
-		if pname.Class != nil {
-			name = *pname.Class
-		}
-		if pname.Name != nil {
-			name = *pname.Name
-		}
-		plugin := makeproc(name)
-		if plugin == nil {
-			return ret, fmt.Errorf("PathRewritePlugin: %s is unknown", name)
-		}
-		err = json.Unmarshal(raw, plugin.GetConfigStruct())
+		_, plugin, err := makePlugin("PathRewritePlugin", raw, func(name string) Plugin {
+			if p := makeproc(name); p != nil {
+				return p
+			}
+			return nil
+		})
 		if err != nil {
 			return ret, err
 		}
-		ret = append(ret, plugin)
+		ret = append(ret, plugin.(PathRewritePlugin))
 	}
 	return ret, nil
 }
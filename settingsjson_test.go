@@ -67,3 +67,119 @@ func TestSettingsJSON_ParseSettingsJSON(t *testing.T) {
 		t.Errorf("invalid result. want = 2, got = %d", len(iplugins))
 	}
 }
+
+type testInputTextPluginConfig struct {
+	Marker *string
+}
+
+type testInputTextPlugin struct {
+	DefaultInputTextPlugin
+	config *testInputTextPluginConfig
+}
+
+func (p *testInputTextPlugin) GetConfigStruct() interface{} {
+	return p.config
+}
+
+func TestSettingsJSON_RegisteredPlugin(t *testing.T) {
+	// the test plugin is registered in its own registry so that the
+	// global one is left as it is
+	registry := newPluginRegistry("InputTextPlugin")
+	registry.register("TestInputTextPlugin", InputTextPluginFactory(func() InputTextPlugin {
+		return &testInputTextPlugin{
+			config: &testInputTextPluginConfig{},
+		}
+	}), []string{"com.example.TestInputTextPlugin"})
+	makeproc := func(name string) InputTextPlugin {
+		if f, ok := registry.lookup(name).(InputTextPluginFactory); ok {
+			return f()
+		}
+		return DefMakeInputTextPlugin(name)
+	}
+
+	settings := NewSettingsJSON()
+	err := settings.ParseSettingsJSON("", strings.NewReader(`{
+  "inputTextPlugin" : [
+    { "class" : "com.example.TestInputTextPlugin", "marker" : "x" },
+    { "name" : "TestInputTextPlugin" },
+    { "class" : "github.com/msnoigrs/gosudachi.DefaultInputTextPlugin" }
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("fail to parse json: %s", err)
+	}
+	iplugins, err := settings.GetInputTextPluginArray(makeproc)
+	if err != nil {
+		t.Fatalf("GetInputTextPluginArray: %s", err)
+	}
+	if len(iplugins) != 3 {
+		t.Fatalf("invalid result. want = 3, got = %d", len(iplugins))
+	}
+	p, ok := iplugins[0].(*testInputTextPlugin)
+	if !ok || p.config.Marker == nil || *p.config.Marker != "x" {
+		t.Errorf("invalid result. the config is not set: %v", iplugins[0])
+	}
+	if iplugins[0] == iplugins[1] {
+		t.Error("invalid result. each plugin must be a new instance")
+	}
+	if DefMakeInputTextPlugin("TestInputTextPlugin") != nil {
+		t.Error("the test plugin must not be in the global registry")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("panic is expected for a name registered twice")
+		}
+	}()
+	registry.register("com.example.TestInputTextPlugin", InputTextPluginFactory(func() InputTextPlugin {
+		return NewDefaultInputTextPlugin(nil)
+	}), nil)
+}
+
+func TestCheckSettings(t *testing.T) {
+	settings := NewSettingsJSON()
+	err := settings.ParseSettingsJSON("", strings.NewReader(`{
+  "path" : "/nonexistent",
+  "systemDict" : "system.dic",
+  "inputTextPlugin" : [
+    { "class" : "com.worksap.nlp.sudachi.UnknownPlugin" },
+    { "class" : "com.worksap.nlp.sudachi.ProlongedSoundMarkInputTextPlugin",
+      "replacementSymbol" : "ー" }
+  ],
+  "oovProviderPlugin" : [
+    { "name" : "SimpleOovProviderPlugin", "cost" : 100 }
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("fail to parse json: %s", err)
+	}
+	effective, problems := CheckSettings(settings)
+
+	if effective.SystemDict != "/nonexistent/system.dic" {
+		t.Errorf("invalid result. want = /nonexistent/system.dic, got = %s", effective.SystemDict)
+	}
+	if len(effective.InputTextPlugin) != 1 {
+		t.Fatalf("invalid result. want = 1, got = %d", len(effective.InputTextPlugin))
+	}
+	p := effective.InputTextPlugin[0]
+	if p["class"] != "ProlongedSoundMarkInputTextPlugin" || p["replacementSymbol"] != "ー" {
+		t.Errorf("invalid result. got = %v", p)
+	}
+	if len(effective.OovProviderPlugin) != 1 || effective.OovProviderPlugin[0]["cost"] != float64(100) {
+		t.Errorf("invalid result. got = %v", effective.OovProviderPlugin)
+	}
+
+	want := []string{
+		"inputTextPlugin[0]: InputTextPlugin: com.worksap.nlp.sudachi.UnknownPlugin is unknown",
+		"fail to read a system dictionary",
+		"inputTextPlugin[1]: ProlongedSoundMarkInputTextPlugin:",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("invalid result. want = %d problems, got = %v", len(want), problems)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(problems[i].Error(), w) {
+			t.Errorf("invalid result. want = %s..., got = %s", w, problems[i])
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package stats counts the frequencies of the morphemes of a corpus.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/msnoigrs/gosudachi"
+)
+
+// KeyNames lists the names of the fields a Counter groups the morphemes
+// by.
+var KeyNames = []string{"surface", "normalized", "dictionary", "reading", "pos"}
+
+type keyFunc func(m *gosudachi.Morpheme) string
+
+// Counter counts the morphemes grouped by the key fields.
+type Counter struct {
+	// Sentences is the number of the morpheme lists added.
+	Sentences int64
+	// Tokens is the number of the morphemes added.
+	Tokens int64
+	// OOV is the number of the out-of-vocabulary morphemes added.
+	OOV int64
+	// Counted is the number of the morphemes passing the filters.
+	Counted int64
+
+	keys    []string
+	fields  []keyFunc
+	include [][]string
+	exclude [][]string
+	counts  map[string]*Entry // by the key fields joined with keySep
+}
+
+// keySep joins the key fields, which may have any other characters.
+const keySep = "\x00"
+
+// Entry is the count of a key.
+type Entry struct {
+	Key   []string `json:"key"`
+	Count int64    `json:"count"`
+}
+
+// NewCounter returns a Counter grouping the morphemes by keys, the names
+// in KeyNames. The key "pos" is the first posDepth fields of the part of
+// speech, or the whole of it if posDepth is not positive.
+func NewCounter(keys []string, posDepth int) (*Counter, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key is given")
+	}
+	fields := make([]keyFunc, len(keys))
+	for i, key := range keys {
+		switch key {
+		case "surface":
+			fields[i] = (*gosudachi.Morpheme).Surface
+		case "normalized":
+			fields[i] = (*gosudachi.Morpheme).NormalizedForm
+		case "dictionary":
+			fields[i] = (*gosudachi.Morpheme).DictionaryForm
+		case "reading":
+			fields[i] = (*gosudachi.Morpheme).ReadingForm
+		case "pos":
+			fields[i] = func(m *gosudachi.Morpheme) string {
+				pos := m.PartOfSpeech()
+				if posDepth > 0 && posDepth < len(pos) {
+					pos = pos[:posDepth]
+				}
+				return strings.Join(pos, ",")
+			}
+		default:
+			return nil, fmt.Errorf("%s is unknown key (%s)", key, strings.Join(KeyNames, "|"))
+		}
+	}
+	return &Counter{
+		keys:   keys,
+		fields: fields,
+		counts: map[string]*Entry{},
+	}, nil
+}
+
+// Keys returns the names of the key fields.
+func (c *Counter) Keys() []string {
+	return c.keys
+}
+
+// Include makes c count only the morphemes whose part of speech starts
+// with one of the prefixes given to Include. A prefix is the comma
+// separated fields of a part of speech, and "*" matches any field.
+func (c *Counter) Include(prefix string) {
+	c.include = append(c.include, strings.Split(prefix, ","))
+}
+
+// Exclude makes c skip the morphemes whose part of speech starts with
+// prefix, in the same form as Include.
+func (c *Counter) Exclude(prefix string) {
+	c.exclude = append(c.exclude, strings.Split(prefix, ","))
+}
+
+func hasPOSPrefix(prefixes [][]string, pos []string) bool {
+	for _, prefix := range prefixes {
+		if len(prefix) > len(pos) {
+			continue
+		}
+		matched := true
+		for i, p := range prefix {
+			if p != "*" && p != pos[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Add counts the morphemes of ms.
+func (c *Counter) Add(ms *gosudachi.MorphemeList) {
+	c.Sentences++
+	for i := 0; i < ms.Length(); i++ {
+		c.AddMorpheme(ms.Get(i))
+	}
+}
+
+// AddMorpheme counts m.
+func (c *Counter) AddMorpheme(m *gosudachi.Morpheme) {
+	c.Tokens++
+	if m.IsOOV() {
+		c.OOV++
+	}
+	pos := m.PartOfSpeech()
+	if len(c.include) > 0 && !hasPOSPrefix(c.include, pos) {
+		return
+	}
+	if hasPOSPrefix(c.exclude, pos) {
+		return
+	}
+	c.Counted++
+
+	key := make([]string, len(c.fields))
+	for i, field := range c.fields {
+		key[i] = field(m)
+	}
+	k := strings.Join(key, keySep)
+	e, ok := c.counts[k]
+	if !ok {
+		e = &Entry{Key: key}
+		c.counts[k] = e
+	}
+	e.Count++
+}
+
+// Types returns the number of the distinct keys counted.
+func (c *Counter) Types() int {
+	return len(c.counts)
+}
+
+// OOVRate returns the ratio of the out-of-vocabulary morphemes to all
+// the morphemes added.
+func (c *Counter) OOVRate() float64 {
+	if c.Tokens == 0 {
+		return 0
+	}
+	return float64(c.OOV) / float64(c.Tokens)
+}
+
+// Top returns the k most frequent entries, or all of them if k is not
+// positive. The entries of the same count are ordered by the key.
+func (c *Counter) Top(k int) []*Entry {
+	entries := make([]*Entry, 0, len(c.counts))
+	for _, e := range c.counts {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return strings.Join(entries[i].Key, keySep) < strings.Join(entries[j].Key, keySep)
+	})
+	if k > 0 && k < len(entries) {
+		entries = entries[:k]
+	}
+	return entries
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/msnoigrs/gosudachi"
+	"github.com/msnoigrs/gosudachi/dictionary"
+)
+
+const testLexicon = `東京,0,0,2816,東京,名詞,固有名詞,地名,一般,*,*,トウキョウ,東京,*,A,*,*,*
+都,0,0,2914,都,名詞,普通名詞,一般,*,*,*,ト,都,*,A,*,*,*
+行く,0,0,5105,行く,動詞,非自立可能,*,*,五段-カ行,終止形-一般,イク,行く,*,A,*,*,*
+`
+
+func newTestDictionary(t *testing.T) *gosudachi.JapaneseDictionary {
+	f, err := ioutil.TempFile("", "stats")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	hb, err := dictionary.NewDictionaryHeader(dictionary.SystemDictVersion, 0, "").ToBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = f.Write(hb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dicbuilder := dictionary.NewDictionaryBuilder(int64(len(hb)), nil, false)
+	store := dictionary.NewPosTable()
+	err = dicbuilder.BuildLexicon(store, strings.NewReader(testLexicon))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteGrammar(store, strings.NewReader("1 1\n0 0 0\n"), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = dicbuilder.WriteLexicon(f, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	var id, cost int16 = 0, 10000
+	dict, err := gosudachi.NewJapaneseDictionary(
+		&gosudachi.BaseConfig{SystemDict: f.Name(), Storage: "heap"},
+		[]gosudachi.InputTextPlugin{},
+		[]gosudachi.OovProviderPlugin{
+			gosudachi.NewSimpleOovProviderPlugin(&gosudachi.SimpleOovProviderPluginConfig{
+				OovPos:  &oovPos,
+				LeftId:  &id,
+				RightId: &id,
+				Cost:    &cost,
+			}),
+		},
+		[]gosudachi.PathRewritePlugin{},
+		[]gosudachi.EditConnectionCostPlugin{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return dict
+}
+
+func TestCounter(t *testing.T) {
+	dict := newTestDictionary(t)
+	defer dict.Close()
+	tokenizer := dict.Create()
+
+	c, err := NewCounter([]string{"dictionary", "pos"}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c.Exclude("名詞,普通名詞")
+	for _, text := range []string{"東京都に行く", "東京に行く"} {
+		ms, err := tokenizer.Tokenize("C", text)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		c.Add(ms)
+	}
+	if c.Sentences != 2 || c.Tokens != 7 || c.OOV != 2 || c.Counted != 4 || c.Types() != 2 {
+		t.Errorf("got sentences %d, tokens %d, oov %d, counted %d, types %d",
+			c.Sentences, c.Tokens, c.OOV, c.Counted, c.Types())
+	}
+	expected := []*Entry{
+		{Key: []string{"東京", "名詞"}, Count: 2},
+		{Key: []string{"行く", "動詞"}, Count: 2},
+	}
+	if got := c.Top(0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+	if got := c.Top(1); len(got) != 1 || got[0].Key[0] != "東京" {
+		t.Errorf("got %v for the top 1", got)
+	}
+
+	c, err = NewCounter([]string{"surface"}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c.Include("名詞,*,地名")
+	ms, err := tokenizer.Tokenize("C", "東京都に行く")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c.Add(ms)
+	if got := c.Top(0); len(got) != 1 || got[0].Key[0] != "東京" {
+		t.Errorf("got %v for 名詞,*,地名", got)
+	}
+
+	if _, err := NewCounter([]string{"lemma"}, 0); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
@@ -34,6 +34,15 @@ func NewJapaneseTokenizer(grammar *dictionary.Grammar, lexicon *dictionary.Lexic
 	return ret
 }
 
+// ValidMode reports whether mode is one of the modes of splitting given to
+// Tokenize, "A", "B" and "C".
+func ValidMode(mode string) bool {
+	return mode == "A" || mode == "B" || mode == "C"
+}
+
+// Tokenize returns the morphemes of text. It fails if a wordInfo read
+// while tokenizing text cannot be read from the storage of the
+// dictionary.
 func (t *JapaneseTokenizer) Tokenize(mode string, text string) (*MorphemeList, error) {
 	inputTextBuilder := NewInputTextBuilder(text, t.grammar)
 
@@ -41,20 +50,17 @@ func (t *JapaneseTokenizer) Tokenize(mode string, text string) (*MorphemeList, e
 		return NewMorphemeList(inputTextBuilder.Build(), t.grammar, t.lexicon, []*LatticeNode{}), nil
 	}
 
-	for _, plugin := range t.inputTextPlugins {
-		err := plugin.Rewrite(inputTextBuilder)
-		if err != nil {
-			return nil, err
-		}
+	input, err := t.rewriteInput(inputTextBuilder)
+	if err != nil {
+		return nil, err
 	}
-	input := inputTextBuilder.Build()
 
 	if t.DumpOutput != nil {
 		fmt.Fprintln(t.DumpOutput, "=== Input dump")
 		fmt.Fprintln(t.DumpOutput, input.GetText())
 	}
 
-	err := t.buildLattice(input)
+	err = t.buildLattice(input)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +74,11 @@ func (t *JapaneseTokenizer) Tokenize(mode string, text string) (*MorphemeList, e
 	if err != nil {
 		return nil, err
 	}
+	err = readWordInfos(path)
+	if err != nil {
+		t.lattice.clear()
+		return nil, err
+	}
 
 	if t.DumpOutput != nil {
 		fmt.Fprintln(t.DumpOutput, "=== Before rewriting:")
@@ -77,10 +88,15 @@ func (t *JapaneseTokenizer) Tokenize(mode string, text string) (*MorphemeList, e
 	for _, plugin := range t.pathRewritePlugins {
 		err := plugin.Rewrite(input, &path, t.lattice)
 		if err != nil {
+			t.lattice.clear()
 			return nil, err
 		}
 	}
+	err = t.lattice.readError()
 	t.lattice.clear()
+	if err != nil {
+		return nil, err
+	}
 
 	if mode != "C" {
 		path = t.splitPath(path, mode)
@@ -92,9 +108,44 @@ func (t *JapaneseTokenizer) Tokenize(mode string, text string) (*MorphemeList, e
 		fmt.Fprintln(t.DumpOutput, "===")
 	}
 
+	err = readWordInfos(path)
+	if err != nil {
+		return nil, err
+	}
+
 	return NewMorphemeList(input, t.grammar, t.lexicon, path), nil
 }
 
+func (t *JapaneseTokenizer) rewriteInput(builder *InputTextBuilder) (*InputText, error) {
+	for _, plugin := range t.inputTextPlugins {
+		err := plugin.Rewrite(builder)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return builder.Build(), nil
+}
+
+// DumpLattice writes the lattice of text to w, which is the same as the
+// "Lattice dump" of DumpOutput.
+func (t *JapaneseTokenizer) DumpLattice(text string, w io.Writer) error {
+	if len(text) == 0 {
+		return nil
+	}
+	input, err := t.rewriteInput(NewInputTextBuilder(text, t.grammar))
+	if err != nil {
+		return err
+	}
+	err = t.buildLattice(input)
+	if err != nil {
+		t.lattice.clear()
+		return err
+	}
+	t.lattice.Dump(w)
+	t.lattice.clear()
+	return nil
+}
+
 func (t *JapaneseTokenizer) buildLattice(input *InputText) error {
 	bytea := input.Bytea
 	t.lattice.resize(len(bytea))
@@ -182,6 +233,18 @@ func (t *JapaneseTokenizer) splitPath(path []*LatticeNode, mode string) []*Latti
 	return newPath
 }
 
+// readWordInfos reads the wordInfos of path in advance so that the
+// plugins and the morphemes do not read the storage.
+func readWordInfos(path []*LatticeNode) error {
+	for _, node := range path {
+		err := node.readWordInfo()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *JapaneseTokenizer) dumpPath(path []*LatticeNode) {
 	for i, node := range path {
 		fmt.Fprintf(t.DumpOutput, "%d: %s\n", i, node.String())
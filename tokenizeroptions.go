@@ -0,0 +1,266 @@
+package gosudachi
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TokenizerOptions changes the plugins of a tokenizer created by
+// JapaneseDictionary.CreateWithOptions. A plugin is referred to by
+// PluginName or by any of the names under which its type is registered,
+// such as the Java class name. A name refers to all the plugins of the
+// same type in the settings, which cannot be told apart.
+type TokenizerOptions struct {
+	// Disable lists the plugins not to be used.
+	Disable []string
+	// Order lists the plugins to be used first, in this order. The
+	// others follow in the order of the settings.
+	Order []string
+	// Replace maps a plugin to the alternative instance of the same
+	// kind, which is set up by JapaneseDictionary.SetUpPlugin.
+	Replace map[string]Plugin
+	// Add lists the plugins not in the settings to be used, which are set
+	// up by JapaneseDictionary.SetUpPlugin. They follow the plugins of
+	// the same kind in the settings, and the other options can refer to
+	// them.
+	Add []Plugin
+}
+
+// resolvedOptions is TokenizerOptions whose names are PluginNames.
+type resolvedOptions struct {
+	disable map[string]bool
+	order   []string
+	ordered map[string]bool
+	replace map[string]Plugin
+}
+
+// PluginName returns the name of a plugin. It is the result of the
+// PluginName method if the plugin has one, or the name of the type,
+// which is the registered name of a built-in plugin.
+func PluginName(p Plugin) string {
+	if n, ok := p.(interface{ PluginName() string }); ok {
+		return n.PluginName()
+	}
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// SetUpPlugin sets up a plugin to be given to TokenizerOptions.Replace or
+// TokenizerOptions.Add.
+// An EditConnectionCostPlugin is not accepted because it edits the
+// grammar shared by all the tokenizers.
+func (d *JapaneseDictionary) SetUpPlugin(p Plugin) error {
+	switch plugin := p.(type) {
+	case InputTextPlugin:
+		return plugin.SetUp()
+	case OovProviderPlugin:
+		return plugin.SetUp(d.grammar)
+	case PathRewritePlugin:
+		return plugin.SetUp(d.grammar)
+	}
+	return fmt.Errorf("%s is not an InputTextPlugin, an OovProviderPlugin nor a PathRewritePlugin", PluginName(p))
+}
+
+// pluginTypeName returns the PluginName of the plugins registered under
+// name, or name itself if it is not registered.
+func pluginTypeName(name string) string {
+	for _, r := range []*pluginRegistry{
+		inputTextPluginRegistry,
+		oovProviderPluginRegistry,
+		pathRewritePluginRegistry,
+	} {
+		if canonical, ok := r.canonicalName(name); ok {
+			return canonical
+		}
+	}
+	return name
+}
+
+// resolve returns o with the names resolved to the PluginNames of
+// plugins. It fails if a name refers to none of plugins.
+func (o *TokenizerOptions) resolve(plugins []Plugin) (*resolvedOptions, error) {
+	known := map[string]bool{}
+	for _, p := range plugins {
+		known[PluginName(p)] = true
+	}
+	lookup := func(name string) (string, error) {
+		n := pluginTypeName(name)
+		if !known[n] {
+			return "", fmt.Errorf("unknown plugin: %s", name)
+		}
+		return n, nil
+	}
+
+	ret := &resolvedOptions{
+		disable: map[string]bool{},
+		ordered: map[string]bool{},
+		replace: map[string]Plugin{},
+	}
+	for _, name := range o.Disable {
+		n, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		ret.disable[n] = true
+	}
+	for _, name := range o.Order {
+		n, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ret.ordered[n] {
+			ret.order = append(ret.order, n)
+			ret.ordered[n] = true
+		}
+	}
+	for name, p := range o.Replace {
+		n, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		ret.replace[n] = p
+	}
+	return ret, nil
+}
+
+// arrange returns the indexes of the plugins named names to be used, in
+// order.
+func (o *resolvedOptions) arrange(names []string) []int {
+	ret := make([]int, 0, len(names))
+	for _, name := range o.order {
+		for i, n := range names {
+			if n == name && !o.disable[n] {
+				ret = append(ret, i)
+			}
+		}
+	}
+	for i, n := range names {
+		if !o.ordered[n] && !o.disable[n] {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func (o *resolvedOptions) inputTextPlugins(plugins []InputTextPlugin) ([]InputTextPlugin, error) {
+	names := make([]string, len(plugins))
+	for i, p := range plugins {
+		names[i] = PluginName(p)
+	}
+	ret := []InputTextPlugin{}
+	for _, i := range o.arrange(names) {
+		p := plugins[i]
+		if r := o.replace[names[i]]; r != nil {
+			var ok bool
+			p, ok = r.(InputTextPlugin)
+			if !ok {
+				return nil, fmt.Errorf("%s is replaced with %s which is not an InputTextPlugin", names[i], PluginName(r))
+			}
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+func (o *resolvedOptions) oovProviderPlugins(plugins []OovProviderPlugin) ([]OovProviderPlugin, error) {
+	names := make([]string, len(plugins))
+	for i, p := range plugins {
+		names[i] = PluginName(p)
+	}
+	ret := []OovProviderPlugin{}
+	for _, i := range o.arrange(names) {
+		p := plugins[i]
+		if r := o.replace[names[i]]; r != nil {
+			var ok bool
+			p, ok = r.(OovProviderPlugin)
+			if !ok {
+				return nil, fmt.Errorf("%s is replaced with %s which is not an OovProviderPlugin", names[i], PluginName(r))
+			}
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+func (o *resolvedOptions) pathRewritePlugins(plugins []PathRewritePlugin) ([]PathRewritePlugin, error) {
+	names := make([]string, len(plugins))
+	for i, p := range plugins {
+		names[i] = PluginName(p)
+	}
+	ret := []PathRewritePlugin{}
+	for _, i := range o.arrange(names) {
+		p := plugins[i]
+		if r := o.replace[names[i]]; r != nil {
+			var ok bool
+			p, ok = r.(PathRewritePlugin)
+			if !ok {
+				return nil, fmt.Errorf("%s is replaced with %s which is not a PathRewritePlugin", names[i], PluginName(r))
+			}
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// CreateWithOptions creates a tokenizer whose plugins are changed by
+// options. The dictionary is shared with the other tokenizers. It fails if
+// options refer to a plugin neither in the settings nor added.
+func (d *JapaneseDictionary) CreateWithOptions(options *TokenizerOptions) (*JapaneseTokenizer, error) {
+	if options == nil {
+		return d.Create(), nil
+	}
+	itps := append([]InputTextPlugin{}, d.inputTextPlugins...)
+	opps := append([]OovProviderPlugin{}, d.oovProviderPlugins...)
+	prps := append([]PathRewritePlugin{}, d.pathRewritePlugins...)
+	for _, p := range options.Add {
+		switch plugin := p.(type) {
+		case InputTextPlugin:
+			itps = append(itps, plugin)
+		case OovProviderPlugin:
+			opps = append(opps, plugin)
+		case PathRewritePlugin:
+			prps = append(prps, plugin)
+		default:
+			return nil, fmt.Errorf("%s is not an InputTextPlugin, an OovProviderPlugin nor a PathRewritePlugin", PluginName(p))
+		}
+	}
+	plugins := []Plugin{}
+	for _, p := range itps {
+		plugins = append(plugins, p)
+	}
+	for _, p := range opps {
+		plugins = append(plugins, p)
+	}
+	for _, p := range prps {
+		plugins = append(plugins, p)
+	}
+	resolved, err := options.resolve(plugins)
+	if err != nil {
+		return nil, err
+	}
+	inputTextPlugins, err := resolved.inputTextPlugins(itps)
+	if err != nil {
+		return nil, err
+	}
+	oovProviderPlugins, err := resolved.oovProviderPlugins(opps)
+	if err != nil {
+		return nil, err
+	}
+	if len(oovProviderPlugins) == 0 {
+		return nil, fmt.Errorf("no OOV provider")
+	}
+	pathRewritePlugins, err := resolved.pathRewritePlugins(prps)
+	if err != nil {
+		return nil, err
+	}
+	return NewJapaneseTokenizer(
+		d.grammar,
+		d.lexicon,
+		inputTextPlugins,
+		oovProviderPlugins,
+		pathRewritePlugins,
+	), nil
+}
@@ -0,0 +1,112 @@
+package gosudachi
+
+import (
+	"testing"
+)
+
+func TestTokenizerOptions(t *testing.T) {
+	def := NewDefaultInputTextPlugin(nil)
+	psm := NewProlongedSoundMarkInputTextPlugin(nil)
+	im := NewIterationMarkInputTextPlugin(nil)
+	markup := NewMarkupInputTextPlugin(nil)
+	plugins := []InputTextPlugin{def, psm, im}
+	all := []Plugin{def, psm, im}
+
+	if name := PluginName(psm); name != "ProlongedSoundMarkInputTextPlugin" {
+		t.Errorf("invalid name: %s", name)
+	}
+
+	options := &TokenizerOptions{
+		Disable: []string{"ProlongedSoundMarkInputTextPlugin"},
+		Order:   []string{"com.worksap.nlp.sudachi.IterationMarkInputTextPlugin"},
+	}
+	resolved, err := options.resolve(all)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := resolved.inputTextPlugins(plugins)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 2 || got[0] != InputTextPlugin(im) || got[1] != InputTextPlugin(def) {
+		t.Errorf("invalid plugins: %v", got)
+	}
+
+	options = &TokenizerOptions{
+		Replace: map[string]Plugin{"DefaultInputTextPlugin": markup},
+	}
+	resolved, err = options.resolve(all)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err = resolved.inputTextPlugins(plugins)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 3 || got[0] != InputTextPlugin(markup) {
+		t.Errorf("invalid plugins: %v", got)
+	}
+
+	options = &TokenizerOptions{
+		Replace: map[string]Plugin{"DefaultInputTextPlugin": NewJoinNumericPlugin(nil)},
+	}
+	resolved, err = options.resolve(all)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = resolved.inputTextPlugins(plugins)
+	if err == nil {
+		t.Error("error is expected for a plugin of another kind")
+	}
+
+	for _, options := range []*TokenizerOptions{
+		{Disable: []string{"ProlongedSoundMark"}},
+		{Order: []string{"MarkupInputTextPlugin"}},
+		{Replace: map[string]Plugin{"JoinNumericPlugin": markup}},
+	} {
+		_, err = options.resolve(all)
+		if err == nil {
+			t.Errorf("error is expected for unknown plugins: %v", options)
+		}
+	}
+}
+
+func TestCreateWithOptionsAdd(t *testing.T) {
+	dict := newTestDictionary(t, nil, nil)
+	defer dict.Close()
+
+	oovPos := []string{"名詞", "普通名詞", "一般", "*", "*", "*"}
+	joinURL := NewJoinURLPlugin(&JoinURLPluginConfig{OovPOS: &oovPos})
+	err := dict.SetUpPlugin(joinURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	text := "見てhttp://example.com/a都に"
+	tests := []struct {
+		options *TokenizerOptions
+		length  int
+	}{
+		{&TokenizerOptions{}, 12},
+		{&TokenizerOptions{Add: []Plugin{joinURL}}, 5},
+		{&TokenizerOptions{Add: []Plugin{joinURL}, Disable: []string{"JoinURLPlugin"}}, 12},
+	}
+	for _, tt := range tests {
+		tokenizer, err := dict.CreateWithOptions(tt.options)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ms, err := tokenizer.Tokenize("C", text)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if ms.Length() != tt.length {
+			t.Errorf("%v: want = %d morphemes, got = %d morphemes", tt.options, tt.length, ms.Length())
+		}
+	}
+
+	_, err = dict.CreateWithOptions(&TokenizerOptions{Add: []Plugin{NewInhibitConnectionPlugin(nil)}})
+	if err == nil {
+		t.Error("error is expected for an EditConnectionCostPlugin")
+	}
+}